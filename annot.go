@@ -0,0 +1,176 @@
+// Copyright 2014 The Go Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Annotations. See PDF 32000-1:2008, §12.5.
+
+package pdf
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Annotation flags, the bits of Annotation.Flags. See PDF 32000-1:2008, §12.5.3.
+const (
+	AnnotInvisible      = 1 << 0 // do not show the annotation if its subtype is unknown
+	AnnotHidden         = 1 << 1 // do not show or print the annotation
+	AnnotPrint          = 1 << 2 // print the annotation
+	AnnotNoZoom         = 1 << 3 // do not scale the annotation with the page
+	AnnotNoRotate       = 1 << 4 // do not rotate the annotation with the page
+	AnnotNoView         = 1 << 5 // print the annotation but do not show it
+	AnnotReadOnly       = 1 << 6 // do not let the user interact with the annotation
+	AnnotLocked         = 1 << 7 // do not let the user delete or change the annotation
+	AnnotToggleNoView   = 1 << 8 // invert AnnotNoView on certain events
+	AnnotLockedContents = 1 << 9 // do not let the user change the annotation's contents
+)
+
+// An Annotation is an object placed on a page, such as a comment, a stamp,
+// a link or a form field.
+type Annotation struct {
+	Subtype  string    // the kind of annotation, such as Text, FreeText, Stamp, Link or Widget
+	Rect     Rect      // where the annotation is placed, in the coordinates of Content
+	Contents string    // the text of the annotation, or a description of it if it shows no text
+	Author   string    // the author of a markup annotation, such as a comment
+	Created  time.Time // when the markup annotation was created; zero if unknown
+	Modified time.Time // when the annotation was last changed; zero if unknown
+	Flags    int       // the annotation flags, such as AnnotHidden
+}
+
+// Annotations returns the page's annotations, in the order of its Annots array.
+func (p Page) Annotations() []Annotation {
+	p = p.fixOptions()
+	annots := p.V.Key("Annots")
+	d := p.display()
+	var out []Annotation
+	for i := 0; i < annots.Len(); i++ {
+		a := annots.Index(i)
+		if a.Kind() != Dict {
+			continue
+		}
+		out = append(out, Annotation{
+			Subtype:  a.Key("Subtype").Name(),
+			Rect:     annotRect(a, d),
+			Contents: a.Key("Contents").Text(),
+			Author:   markup(a, "T").Text(),
+			Created:  parseDate(markup(a, "CreationDate").Text()),
+			Modified: parseDate(a.Key("M").Text()),
+			Flags:    int(a.Key("F").Int64()),
+		})
+	}
+	return out
+}
+
+// markup returns the entry key of a, if a is a markup annotation.
+// Other annotations, such as widgets, use the same keys for other things.
+func markup(a Value, key string) Value {
+	switch a.Key("Subtype").Name() {
+	case "Link", "Popup", "Widget", "Screen", "PrinterMark", "TrapNet", "Watermark", "3D":
+		return Value{}
+	}
+	return a.Key(key)
+}
+
+// annotRect returns the rectangle of the annotation a,
+// in display space if d is not nil.
+func annotRect(a Value, d *display) Rect {
+	r := pageBox(a.Key("Rect"), Rect{})
+	if d != nil {
+		r = d.flipRect(d.m.rect(r))
+	}
+	return r
+}
+
+// An appearance is the normal appearance of an annotation, a form,
+// with the matrix that maps the form onto the annotation's rectangle.
+type appearance struct {
+	form Value
+	m    matrix
+}
+
+// appearances returns the normal appearances of the page's shown
+// annotations, if the extraction options include annotations.
+func (p Page) appearances(oc *optionalContent) []appearance {
+	if !p.extractOptions().Annotations {
+		return nil
+	}
+	annots := p.V.Key("Annots")
+	var out []appearance
+	for i := 0; i < annots.Len(); i++ {
+		a := annots.Index(i)
+		if a.Key("F").Int64()&(AnnotHidden|AnnotNoView) != 0 || !oc.visible(a.Key("OC")) {
+			continue
+		}
+		form := a.Key("AP").Key("N")
+		if form.Kind() == Dict { // appearances for each state, such as a check box's On and Off
+			form = form.Key(a.Key("AS").Name())
+		}
+		if !isForm(form) || !oc.visible(form.Key("OC")) {
+			continue
+		}
+		out = append(out, appearance{form, appearanceMatrix(form, pageBox(a.Key("Rect"), Rect{}))})
+	}
+	return out
+}
+
+// appearanceMatrix returns the matrix that maps the space of the
+// appearance form to the page, fitting the form's bounding box, as
+// transformed by its Matrix, to the annotation's rectangle r.
+// See PDF 32000-1:2008, §12.5.5.
+func appearanceMatrix(form Value, r Rect) matrix {
+	m := formMatrix(form)
+	box := m.rect(pageBox(form.Key("BBox"), Rect{}))
+	sx, sy := 1.0, 1.0
+	if w := box.Max.X - box.Min.X; w > 0 {
+		sx = (r.Max.X - r.Min.X) / w
+	}
+	if h := box.Max.Y - box.Min.Y; h > 0 {
+		sy = (r.Max.Y - r.Min.Y) / h
+	}
+	return m.mul(matrix{
+		{sx, 0, 0},
+		{0, sy, 0},
+		{r.Min.X - box.Min.X*sx, r.Min.Y - box.Min.Y*sy, 1},
+	})
+}
+
+// parseDate parses a date string, D:YYYYMMDDHHmmSSOHH'mm', in which all
+// fields after the year are optional. It returns the zero time if s is
+// not a date. See PDF 32000-1:2008, §7.9.4.
+func parseDate(s string) time.Time {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "D:")
+	field := func(n, def int) int {
+		if len(s) < n {
+			return def
+		}
+		v, err := strconv.Atoi(s[:n])
+		if err != nil {
+			return -1
+		}
+		s = s[n:]
+		return v
+	}
+	year := field(4, -1)
+	month := field(2, 1)
+	day := field(2, 1)
+	hour := field(2, 0)
+	min := field(2, 0)
+	sec := field(2, 0)
+	if year < 0 || month < 1 || month > 12 || day < 1 || day > 31 || hour < 0 || min < 0 || sec < 0 {
+		return time.Time{}
+	}
+	loc := time.UTC
+	if s != "" && (s[0] == '+' || s[0] == '-') {
+		sign := 1
+		if s[0] == '-' {
+			sign = -1
+		}
+		s = strings.Replace(s[1:], "'", "", -1)
+		h, m := field(2, 0), field(2, 0)
+		if h >= 0 && m >= 0 {
+			loc = time.FixedZone("", sign*(h*3600+m*60))
+		}
+	}
+	return time.Date(year, time.Month(month), day, hour, min, sec, 0, loc)
+}
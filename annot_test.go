@@ -0,0 +1,66 @@
+package pdf
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bmizerany/assert"
+)
+
+func TestAnnotations(t *testing.T) {
+	r := newTestPDF(t,
+		`<< /Type /Catalog /Pages 2 0 R >>`,
+		`<< /Type /Pages /Kids [3 0 R] /Count 1 >>`,
+		`<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 4 0 R
+		   /Resources << /Font << /F1 << /Type /Font /Subtype /Type1 /BaseFont /Helvetica >> >> >>
+		   /Annots [
+		     << /Type /Annot /Subtype /Text /Rect [50 50 70 70] /Contents (Check this) /T (Ana)
+		        /M (D:20240102030405+01'00') /CreationDate (D:2024) /F 4 >>
+		     << /Type /Annot /Subtype /Stamp /Rect [300 300 400 350] /AP << /N 5 0 R >> >>
+		     << /Type /Annot /Subtype /Widget /Rect [100 100 200 120] /T (name) /F 2 /AP << /N 6 0 R >> >>
+		     << /Type /Annot /Subtype /Widget /Rect [100 600 200 620] /T (agree) /AS /On
+		        /AP << /N << /On 6 0 R /Off 5 0 R >> >> >>
+		   ] >>`,
+		`stream
+BT /F1 12 Tf 1 0 0 1 72 700 Tm (Body) Tj ET`,
+		`<< /Type /XObject /Subtype /Form /BBox [0 0 50 25]
+		   /Resources << /Font << /F1 << /Type /Font /Subtype /Type1 /BaseFont /Courier >> >> >> >>
+stream
+BT /F1 10 Tf 1 0 0 1 5 5 Tm (Approved) Tj ET`,
+		`<< /Type /XObject /Subtype /Form /BBox [0 0 100 20]
+		   /Resources << /Font << /F1 << /Type /Font /Subtype /Type1 /BaseFont /Helvetica >> >> >> >>
+stream
+BT /F1 10 Tf 1 0 0 1 2 5 Tm (Yes) Tj ET`,
+	)
+	p := r.Page(1)
+	annots := p.Annotations()
+	assert.Equal(t, 4, len(annots))
+	assert.Equal(t, "Text", annots[0].Subtype)
+	assert.Equal(t, Rect{Point{50, 50}, Point{70, 70}}, annots[0].Rect)
+	assert.Equal(t, "Check this", annots[0].Contents)
+	assert.Equal(t, "Ana", annots[0].Author)
+	assert.Equal(t, AnnotPrint, annots[0].Flags)
+	assert.Equal(t, time.Date(2024, 1, 2, 2, 4, 5, 0, time.UTC), annots[0].Modified.UTC())
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), annots[0].Created)
+	assert.Equal(t, "", annots[2].Author) // a widget's T is its field name
+
+	text, err := p.GetPlainText(nil)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "Body\n", text)
+
+	r.SetExtractOptions(ExtractOptions{Annotations: true})
+	text, err = p.GetPlainText(nil)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "Body\nApproved\nYes\n", text)
+	rows, err := p.GetTextByRow()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 3, len(rows))
+	for _, g := range p.Content().Text {
+		if g.S == "A" {
+			assert.Equal(t, "Courier", g.Font)
+			assert.Equal(t, 310.0, g.X)
+			assert.Equal(t, 310.0, g.Y)
+			assert.Equal(t, 20.0, g.FontSize)
+		}
+	}
+}
@@ -51,3 +51,30 @@ func (a *alphaReader) Read(p []byte) (int, error) {
 	copy(p, buf)
 	return n, nil
 }
+
+// asciiHexDecode decodes the data of an ASCIIHexDecode stream: pairs of
+// hex digits, among white space, up to '>'. An odd final digit is
+// taken to be followed by 0.
+func asciiHexDecode(data []byte) []byte {
+	var out []byte
+	half := -1
+	for _, c := range data {
+		if c == '>' {
+			break
+		}
+		x := unhex(c)
+		if x < 0 {
+			continue
+		}
+		if half < 0 {
+			half = x
+			continue
+		}
+		out = append(out, byte(half<<4|x))
+		half = -1
+	}
+	if half >= 0 {
+		out = append(out, byte(half<<4))
+	}
+	return out
+}
@@ -0,0 +1,254 @@
+// Copyright 2014 The Go Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Bidirectional text. See Unicode Standard Annex #9,
+// Unicode Bidirectional Algorithm (https://www.unicode.org/reports/tr9/).
+
+package pdf
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/bidi"
+	"golang.org/x/text/unicode/norm"
+)
+
+// bidiClass returns the bidirectional class of s, a glyph's text:
+// that of its first strongly directional character, if any,
+// and otherwise that of its first character.
+func bidiClass(s string) bidi.Class {
+	first := bidi.ON
+	for i, r := range s {
+		p, _ := bidi.LookupRune(r)
+		c := p.Class()
+		switch c {
+		case bidi.L, bidi.R, bidi.AL:
+			return c
+		}
+		if i == 0 {
+			first = c
+		}
+	}
+	return first
+}
+
+// isRTL reports whether s contains right-to-left characters.
+func isRTL(s string) bool {
+	for _, r := range s {
+		if r < 0x0590 {
+			continue
+		}
+		if p, _ := bidi.LookupRune(r); p.Class() == bidi.R || p.Class() == bidi.AL {
+			return true
+		}
+	}
+	return false
+}
+
+// visualToLogical returns the reading order of a line of units, such as
+// glyphs or words, given in the order they appear from left to right.
+//
+// The levels of the units are resolved by the rules of the Unicode
+// Bidirectional Algorithm for text with no explicit embeddings, and the
+// runs of units at odd levels are reversed as rule L2 reverses them for
+// display. Reversal is its own inverse, so this restores reading order.
+// The line is taken to be right to left if it holds at least as many
+// right-to-left units as left-to-right ones.
+func visualToLogical(classes []bidi.Class) []int {
+	n := len(classes)
+	types := append([]bidi.Class(nil), classes...)
+	ltr, rtl := 0, 0
+	for _, c := range types {
+		switch c {
+		case bidi.L:
+			ltr++
+		case bidi.R, bidi.AL:
+			rtl++
+		}
+	}
+	base, sos := 0, bidi.L
+	if rtl > 0 && rtl >= ltr {
+		base, sos = 1, bidi.R
+	}
+
+	// W1: nonspacing marks take the type of the unit before them.
+	prev := sos
+	for i, c := range types {
+		if c == bidi.NSM {
+			types[i] = prev
+		} else {
+			prev = c
+		}
+	}
+	// W2, W3: European numbers after Arabic letters are Arabic numbers,
+	// and Arabic letters are right to left.
+	last := sos
+	for i, c := range types {
+		switch c {
+		case bidi.L, bidi.R:
+			last = c
+		case bidi.AL:
+			last = c
+			types[i] = bidi.R
+		case bidi.EN:
+			if last == bidi.AL {
+				types[i] = bidi.AN
+			}
+		}
+	}
+	// W4: a single separator between two numbers of the same type joins them.
+	for i := 1; i+1 < n; i++ {
+		before, after := types[i-1], types[i+1]
+		switch {
+		case types[i] == bidi.ES && before == bidi.EN && after == bidi.EN:
+			types[i] = bidi.EN
+		case types[i] == bidi.CS && before == after && (before == bidi.EN || before == bidi.AN):
+			types[i] = before
+		}
+	}
+	// W5: terminators next to European numbers become European numbers.
+	for i := 0; i < n; i++ {
+		if types[i] != bidi.ET {
+			continue
+		}
+		j := i
+		for j < n && types[j] == bidi.ET {
+			j++
+		}
+		if i > 0 && types[i-1] == bidi.EN || j < n && types[j] == bidi.EN {
+			for k := i; k < j; k++ {
+				types[k] = bidi.EN
+			}
+		}
+		i = j
+	}
+	// W6, W7: other separators and terminators are neutral, and European
+	// numbers in left-to-right text are left to right.
+	last = sos
+	for i, c := range types {
+		switch c {
+		case bidi.ES, bidi.ET, bidi.CS:
+			types[i] = bidi.ON
+		case bidi.L, bidi.R:
+			last = c
+		case bidi.EN:
+			if last == bidi.L {
+				types[i] = bidi.L
+			}
+		}
+	}
+	// N1, N2: neutrals between units of the same direction take that
+	// direction, and otherwise the direction of the line.
+	strong := func(c bidi.Class) (bidi.Class, bool) {
+		switch c {
+		case bidi.L:
+			return bidi.L, true
+		case bidi.R, bidi.EN, bidi.AN:
+			return bidi.R, true
+		}
+		return 0, false
+	}
+	for i := 0; i < n; i++ {
+		if _, ok := strong(types[i]); ok {
+			continue
+		}
+		j := i
+		for j < n {
+			if _, ok := strong(types[j]); ok {
+				break
+			}
+			j++
+		}
+		before, after := sos, sos
+		if i > 0 {
+			before, _ = strong(types[i-1])
+		}
+		if j < n {
+			after, _ = strong(types[j])
+		}
+		dir := sos
+		if before == after {
+			dir = before
+		}
+		for k := i; k < j; k++ {
+			types[k] = dir
+		}
+		i = j
+	}
+
+	// I1, I2: implicit levels.
+	levels := make([]int, n)
+	for i, c := range types {
+		switch {
+		case base == 0 && c == bidi.R:
+			levels[i] = 1
+		case base == 0 && (c == bidi.EN || c == bidi.AN):
+			levels[i] = 2
+		case base == 1 && c != bidi.R:
+			levels[i] = 2
+		default:
+			levels[i] = base
+		}
+	}
+	// L1: whitespace at the end of the line is at the line's level.
+	for i := n - 1; i >= 0; i-- {
+		if c := classes[i]; c != bidi.WS && c != bidi.S {
+			break
+		}
+		levels[i] = base
+	}
+
+	// L2: from the highest level down to the lowest odd level,
+	// reverse every run of units at that level or higher.
+	order := make([]int, n)
+	max := 0
+	for i := range order {
+		order[i] = i
+		if levels[i] > max {
+			max = levels[i]
+		}
+	}
+	for level := max; level >= 1; level-- {
+		for i := 0; i < n; i++ {
+			if levels[i] < level {
+				continue
+			}
+			j := i
+			for j < n && levels[j] >= level {
+				j++
+			}
+			for a, b := i, j-1; a < b; a, b = a+1, b-1 {
+				order[a], order[b] = order[b], order[a]
+			}
+			i = j
+		}
+	}
+	return order
+}
+
+// arabicBase replaces the Arabic presentation forms in s, the contextual
+// shapes and ligatures that some fonts map characters to, with the
+// letters they show.
+func arabicBase(s string) string {
+	if strings.IndexFunc(s, isArabicForm) < 0 {
+		return s
+	}
+	var b strings.Builder
+	for len(s) > 0 {
+		r, size := utf8.DecodeRuneInString(s)
+		if isArabicForm(r) {
+			b.WriteString(norm.NFKC.String(s[:size]))
+		} else {
+			b.WriteString(s[:size])
+		}
+		s = s[size:]
+	}
+	return b.String()
+}
+
+// isArabicForm reports whether r is in one of the Arabic Presentation Forms blocks.
+func isArabicForm(r rune) bool {
+	return 0xFB50 <= r && r <= 0xFDFF || 0xFE70 <= r && r <= 0xFEFE
+}
@@ -0,0 +1,64 @@
+package pdf
+
+import (
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+// rtlGlyphs returns the glyphs of s laid out from x, one per rune,
+// each advancing by 6 points in the direction of step.
+func rtlGlyphs(s string, x, step float64) []Text {
+	var text []Text
+	for _, r := range s {
+		if step < 0 {
+			x += step
+		}
+		text = append(text, Text{FontSize: 10, X: x, Y: 700, W: 6, S: string(r)})
+		if step > 0 {
+			x += step
+		}
+	}
+	return text
+}
+
+func TestBidiWords(t *testing.T) {
+	tests := []struct {
+		text []Text
+		want string
+	}{
+		// Shown left to right, in visual order.
+		{rtlGlyphs("םלוע םולש", 100, 6), "שלום עולם\n"},
+		// Shown right to left, in reading order.
+		{rtlGlyphs("שלום עולם", 200, -6), "שלום עולם\n"},
+		// Left-to-right text and numbers within a right-to-left line.
+		{rtlGlyphs("םלוע abc 2020 םולש", 100, 6), "שלום abc 2020 עולם\n"},
+		// A right-to-left word within a left-to-right line.
+		{rtlGlyphs("the word םולש means peace", 100, 6), "the word שלום means peace\n"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, plainText(words(tt.text)))
+	}
+
+	w := words(rtlGlyphs("םולש", 100, 6))
+	assert.Equal(t, 1, len(w))
+	assert.Equal(t, Rect{Point{100, 698}, Point{124, 708}}, w[0].Rect)
+}
+
+func TestBidiReadingOrder(t *testing.T) {
+	var ws []Word
+	for _, s := range []string{"עולם", "abc", "def", "שלום"} {
+		ws = append(ws, Word{S: s})
+	}
+	var got []string
+	for _, w := range readingOrder(ws) {
+		got = append(got, w.S)
+	}
+	assert.Equal(t, []string{"שלום", "abc", "def", "עולם"}, got)
+}
+
+func TestArabicBase(t *testing.T) {
+	assert.Equal(t, "مرحبا", arabicBase("ﻣﺮﺣﺒﺎ"))
+	assert.Equal(t, "لا", arabicBase("ﻻ"))
+	assert.Equal(t, "abc", arabicBase("abc"))
+}
@@ -0,0 +1,241 @@
+// Copyright 2014 The Go Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Colour spaces. See PDF 32000-1:2008, §8.6.
+
+package pdf
+
+import (
+	"image/color"
+	"io/ioutil"
+	"math"
+)
+
+// Text rendering modes, set by the Tr operator. See PDF 32000-1:2008, §9.3.6.
+const (
+	RenderFill           = 0 // fill the glyphs
+	RenderStroke         = 1 // stroke the glyph outlines
+	RenderFillStroke     = 2 // fill, then stroke
+	RenderInvisible      = 3 // neither fill nor stroke
+	RenderFillClip       = 4 // fill and add to the clipping path
+	RenderStrokeClip     = 5 // stroke and add to the clipping path
+	RenderFillStrokeClip = 6 // fill, stroke and add to the clipping path
+	RenderClip           = 7 // add to the clipping path only
+)
+
+var (
+	black = color.RGBA{0, 0, 0, 255}
+	white = color.RGBA{255, 255, 255, 255}
+)
+
+// colorSpace returns the colour space selected by the operand of cs or CS:
+// one of the device colour spaces, named directly, or an entry in the
+// ColorSpace subdictionary of the resources res.
+func colorSpace(v, res Value) Value {
+	switch v.Name() {
+	case "DeviceGray", "DeviceRGB", "DeviceCMYK", "Pattern":
+		return v
+	}
+	if cs := res.Key("ColorSpace").Key(v.Name()); !cs.IsNull() {
+		return cs
+	}
+	return v
+}
+
+// colorFamily returns the family of the colour space cs, such as DeviceRGB or ICCBased.
+func colorFamily(cs Value) string {
+	if cs.Kind() == Array {
+		return cs.Index(0).Name()
+	}
+	return cs.Name()
+}
+
+// initialColor returns the colour that selecting the colour space cs sets.
+func initialColor(cs Value) color.RGBA {
+	switch colorFamily(cs) {
+	case "Indexed":
+		return toRGB(cs, []float64{0})
+	case "Separation", "DeviceN":
+		tints := make([]float64, colorComponents(cs))
+		for i := range tints {
+			tints[i] = 1
+		}
+		return toRGB(cs, tints)
+	case "Lab":
+		return toRGB(cs, []float64{0, 0, 0})
+	}
+	return black
+}
+
+// colorComponents returns the number of colour components in cs.
+func colorComponents(cs Value) int {
+	switch colorFamily(cs) {
+	case "DeviceRGB", "CalRGB", "Lab", "RGB":
+		return 3
+	case "DeviceCMYK", "CMYK":
+		return 4
+	case "ICCBased":
+		return int(cs.Index(1).Key("N").Int64())
+	case "DeviceN":
+		return cs.Index(1).Len()
+	}
+	return 1
+}
+
+// maxColorDepth limits how deeply colour spaces based on other colour
+// spaces are followed, in case a colour space refers to itself.
+const maxColorDepth = 8
+
+// toRGB converts the colour with components c in the colour space cs to RGB.
+// Colour spaces are converted naively, ignoring colour profiles and
+// rendering intents; patterns are taken to be black.
+func toRGB(cs Value, c []float64) color.RGBA {
+	return convertRGB(cs, c, 0)
+}
+
+// convertRGB is toRGB for a colour space that depth other colour
+// spaces are based on. Beyond maxColorDepth, colours are taken to be gray.
+func convertRGB(cs Value, c []float64, depth int) color.RGBA {
+	at := func(i int) float64 {
+		if i < len(c) {
+			return c[i]
+		}
+		return 0
+	}
+	if depth >= maxColorDepth {
+		return rgb(at(0), at(0), at(0))
+	}
+	switch colorFamily(cs) {
+	case "DeviceGray", "CalGray", "G":
+		return rgb(at(0), at(0), at(0))
+	case "DeviceRGB", "CalRGB", "RGB":
+		return rgb(at(0), at(1), at(2))
+	case "DeviceCMYK", "CMYK":
+		return cmyk(at(0), at(1), at(2), at(3))
+	case "ICCBased":
+		if alt := cs.Index(1).Key("Alternate"); !alt.IsNull() {
+			return convertRGB(alt, c, depth+1)
+		}
+		switch len(c) {
+		case 3:
+			return rgb(at(0), at(1), at(2))
+		case 4:
+			return cmyk(at(0), at(1), at(2), at(3))
+		}
+		return rgb(at(0), at(0), at(0))
+	case "Lab":
+		return lab(cs.Index(1).Key("WhitePoint"), at(0), at(1), at(2))
+	case "Indexed":
+		base := cs.Index(1)
+		n := colorComponents(base)
+		var table []byte
+		switch lookup := cs.Index(3); lookup.Kind() {
+		case String:
+			table = []byte(lookup.RawString())
+		case Stream:
+			table, _ = ioutil.ReadAll(lookup.Reader())
+		}
+		i := int(at(0))
+		if i < 0 || (i+1)*n > len(table) {
+			return black
+		}
+		comps := make([]float64, n)
+		for j := range comps {
+			comps[j] = float64(table[i*n+j]) / 255
+		}
+		if colorFamily(base) == "Lab" { // components span the Lab ranges
+			comps[0] *= 100
+			comps[1] = comps[1]*200 - 100
+			comps[2] = comps[2]*200 - 100
+		}
+		return convertRGB(base, comps, depth+1)
+	case "Separation", "DeviceN":
+		alt := cs.Index(2)
+		if out, ok := evalExponential(cs.Index(3), c); ok && len(out) == colorComponents(alt) {
+			return convertRGB(alt, out, depth+1)
+		}
+		// Without a tint transform to evaluate, show colorant tints as gray.
+		tint := 0.0
+		for _, t := range c {
+			tint = math.Max(tint, t)
+		}
+		return rgb(1-tint, 1-tint, 1-tint)
+	}
+	return black
+}
+
+// evalExponential evaluates the function f at x if it is an
+// exponential interpolation function (FunctionType 2) of one input.
+// See PDF 32000-1:2008, §7.10.3.
+func evalExponential(f Value, x []float64) ([]float64, bool) {
+	if f.Key("FunctionType").Int64() != 2 || len(x) != 1 {
+		return nil, false
+	}
+	c0, c1 := f.Key("C0"), f.Key("C1")
+	n := c1.Len()
+	if c1.IsNull() {
+		n = 1
+	}
+	out := make([]float64, n)
+	t := math.Pow(x[0], f.Key("N").Float64())
+	for i := range out {
+		lo, hi := 0.0, 1.0
+		if c0.Kind() == Array {
+			lo = c0.Index(i).Float64()
+		}
+		if c1.Kind() == Array {
+			hi = c1.Index(i).Float64()
+		}
+		out[i] = lo + t*(hi-lo)
+	}
+	return out, true
+}
+
+func rgb(r, g, b float64) color.RGBA {
+	return color.RGBA{component(r), component(g), component(b), 255}
+}
+
+func cmyk(c, m, y, k float64) color.RGBA {
+	return rgb((1-c)*(1-k), (1-m)*(1-k), (1-y)*(1-k))
+}
+
+// lab converts a CIE L*a*b* colour with the given white point to sRGB.
+func lab(whitePoint Value, l, a, b float64) color.RGBA {
+	wx, wy, wz := 0.9505, 1.0, 1.089
+	if whitePoint.Len() == 3 {
+		wx, wy, wz = whitePoint.Index(0).Float64(), whitePoint.Index(1).Float64(), whitePoint.Index(2).Float64()
+	}
+	g := func(x float64) float64 {
+		if x >= 6.0/29 {
+			return x * x * x
+		}
+		return 108.0 / 841 * (x - 4.0/29)
+	}
+	m := (l + 16) / 116
+	x := wx * g(m+a/500)
+	y := wy * g(m)
+	z := wz * g(m-b/200)
+	gamma := func(v float64) float64 {
+		if v <= 0.0031308 {
+			return 12.92 * v
+		}
+		return 1.055*math.Pow(v, 1/2.4) - 0.055
+	}
+	return rgb(
+		gamma(3.2406*x-1.5372*y-0.4986*z),
+		gamma(-0.9689*x+1.8758*y+0.0415*z),
+		gamma(0.0557*x-0.2040*y+1.0570*z),
+	)
+}
+
+// component converts a colour component in the range 0 to 1 to a byte.
+func component(v float64) uint8 {
+	if math.IsNaN(v) || v <= 0 {
+		return 0
+	}
+	if v >= 1 {
+		return 255
+	}
+	return uint8(v*255 + 0.5)
+}
@@ -0,0 +1,176 @@
+// Copyright 2014 The Go Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pdf
+
+import "math"
+
+// A display maps a page's user space to the space of the page as it is
+// displayed, as selected by ExtractOptions.DisplaySpace.
+//
+// The mapping is done in two steps. The matrix m maps user space to the
+// page's upright space, in which the crop box is turned by the page's
+// Rotate to stand upright with its lower left corner at the origin, and is
+// scaled by UserUnit and the DPI. Text is analyzed in upright space, where
+// lines run left to right and Y increases up the page, as in user space.
+// Results are then flipped to display space by subtracting their Y
+// coordinates from the page's height, putting the origin at the top left.
+type display struct {
+	m      matrix
+	box    Rect    // the crop box in upright space
+	height float64 // the height of the page in display space
+}
+
+// display returns the mapping to display space if the
+// extraction options select it, or nil if they do not.
+func (p Page) display() *display {
+	opts := p.extractOptions()
+	if !opts.DisplaySpace {
+		return nil
+	}
+	box := pageBox(p.MediaBox(), Rect{Point{0, 0}, Point{612, 792}})
+	if crop := pageBox(p.CropBox(), box); crop.Min.X < crop.Max.X && crop.Min.Y < crop.Max.Y {
+		box = intersect(box, crop)
+	}
+	scale := 1.0
+	if u := p.findInherited("UserUnit"); u.Kind() == Integer || u.Kind() == Real {
+		scale = u.Float64()
+	}
+	if opts.DPI > 0 {
+		scale *= opts.DPI / 72
+	}
+
+	w, h := box.Max.X-box.Min.X, box.Max.Y-box.Min.Y
+	m := matrix{{1, 0, 0}, {0, 1, 0}, {-box.Min.X, -box.Min.Y, 1}}
+	switch (int(p.findInherited("Rotate").Int64())%360 + 360) % 360 {
+	case 90: // turned clockwise when displayed
+		m = m.mul(matrix{{0, -1, 0}, {1, 0, 0}, {0, w, 1}})
+		w, h = h, w
+	case 180:
+		m = m.mul(matrix{{-1, 0, 0}, {0, -1, 0}, {w, h, 1}})
+	case 270:
+		m = m.mul(matrix{{0, 1, 0}, {-1, 0, 0}, {h, 0, 1}})
+		w, h = h, w
+	}
+	m = m.mul(matrix{{scale, 0, 0}, {0, scale, 0}, {0, 0, 1}})
+	return &display{m: m, box: Rect{Point{0, 0}, Point{w * scale, h * scale}}, height: h * scale}
+}
+
+// uprightBox returns the page's crop box in upright space, the
+// space of the results of content: the crop box of display space,
+// or the crop box within the media box in user space.
+func (p Page) uprightBox() Rect {
+	if d := p.display(); d != nil {
+		return d.box
+	}
+	box := pageBox(p.MediaBox(), Rect{Point{0, 0}, Point{612, 792}})
+	if crop := intersect(box, pageBox(p.CropBox(), box)); crop.Min.X < crop.Max.X && crop.Min.Y < crop.Max.Y {
+		box = crop
+	}
+	return box
+}
+
+// pageBox returns the rectangle given by the page boundary v,
+// or def if v is not a rectangle.
+func pageBox(v Value, def Rect) Rect {
+	if v.Len() != 4 {
+		return def
+	}
+	x0, y0, x1, y1 := v.Index(0).Float64(), v.Index(1).Float64(), v.Index(2).Float64(), v.Index(3).Float64()
+	return Rect{Point{math.Min(x0, x1), math.Min(y0, y1)}, Point{math.Max(x0, x1), math.Max(y0, y1)}}
+}
+
+// clip removes the text and rectangles of c, in upright space,
+// that lie outside the crop box.
+func (d *display) clip(c *Content) {
+	text := c.Text[:0]
+	for _, t := range c.Text {
+		if t.S == "\n" || contains(d.box, Point{t.X, t.Y}) {
+			text = append(text, t)
+		}
+	}
+	c.Text = text
+	rect := c.Rect[:0]
+	for _, r := range c.Rect {
+		r = intersect(r, d.box)
+		if r.Min.X <= r.Max.X && r.Min.Y <= r.Max.Y {
+			rect = append(rect, r)
+		}
+	}
+	c.Rect = rect
+}
+
+// The flip methods map results from upright space to display space.
+// They do nothing if d is nil.
+
+func (d *display) flipY(y float64) float64 {
+	if d == nil {
+		return y
+	}
+	return d.height - y
+}
+
+func (d *display) flipRect(r Rect) Rect {
+	if d == nil {
+		return r
+	}
+	return Rect{Point{r.Min.X, d.height - r.Max.Y}, Point{r.Max.X, d.height - r.Min.Y}}
+}
+
+func (d *display) flipText(text []Text) {
+	if d == nil {
+		return
+	}
+	for i := range text {
+		t := &text[i]
+		t.Y = d.height - t.Y
+		if t.Quad != ([4]Point{}) {
+			for j := range t.Quad {
+				t.Quad[j].Y = d.height - t.Quad[j].Y
+			}
+		}
+	}
+}
+
+func (d *display) flipWords(words []Word) {
+	if d == nil {
+		return
+	}
+	for i := range words {
+		words[i].Y = d.height - words[i].Y
+		words[i].Rect = d.flipRect(words[i].Rect)
+	}
+}
+
+func (d *display) flipLayout(l *Layout) {
+	if d == nil {
+		return
+	}
+	l.Rect = d.flipRect(l.Rect)
+	for i := range l.Blocks {
+		b := &l.Blocks[i]
+		b.Rect = d.flipRect(b.Rect)
+		for j := range b.Lines {
+			b.Lines[j].Rect = d.flipRect(b.Lines[j].Rect)
+			d.flipWords(b.Lines[j].Words)
+		}
+	}
+}
+
+func (d *display) flipTables(tables []Table) {
+	if d == nil {
+		return
+	}
+	for i := range tables {
+		t := &tables[i]
+		t.Rect = d.flipRect(t.Rect)
+		for j := range t.Rows {
+			row := &t.Rows[j]
+			row.Rect = d.flipRect(row.Rect)
+			for k := range row.Cells {
+				row.Cells[k].Rect = d.flipRect(row.Cells[k].Rect)
+			}
+		}
+	}
+}
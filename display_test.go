@@ -0,0 +1,56 @@
+package pdf
+
+import (
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+func TestDisplaySpace(t *testing.T) {
+	r := newTestPDF(t,
+		`<< /Type /Catalog /Pages 2 0 R >>`,
+		`<< /Type /Pages /Kids [3 0 R 5 0 R] /Count 2
+		   /Resources << /Font << /F1 << /Type /Font /Subtype /Type1 /BaseFont /Helvetica >> >> >> >>`,
+		`<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Rotate 90 /Contents 4 0 R >>`,
+		`stream
+0 1 -1 0 612 0 cm
+BT /F1 10 Tf 1 0 0 1 100 100 Tm (Landscape) Tj 1 0 0 1 100 80 Tm (page) Tj ET`,
+		`<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /CropBox [0 0 300 400] /Contents 6 0 R >>`,
+		`stream
+BT /F1 10 Tf 1 0 0 1 100 100 Tm (In) Tj 1 0 0 1 500 500 Tm (Out) Tj ET
+100 100 300 300 re f`,
+	)
+	p := r.Page(1)
+	text := p.Content().Text
+	assert.Equal(t, 90.0, text[0].Angle)
+
+	r.SetExtractOptions(ExtractOptions{DisplaySpace: true, DPI: 144})
+	assert.Equal(t, "Landscape\npage\n", mustPlainText(t, p))
+	text = p.Content().Text
+	assert.Equal(t, 0.0, text[0].Angle)
+	assert.Equal(t, 20.0, text[0].FontSize)
+	assert.Equal(t, Point{200, 1024}, Point{text[0].X, text[0].Y})
+	words := p.Words()
+	assert.Equal(t, "page", words[1].S)
+	assert.Equal(t, Point{200, 1064}, Point{words[1].X, words[1].Y})
+
+	r.SetExtractOptions(ExtractOptions{DisplaySpace: true})
+	c := r.Page(2).Content()
+	assert.Equal(t, 2, len(c.Text))
+	assert.Equal(t, "I", c.Text[0].S)
+	assert.Equal(t, 300.0, c.Text[0].Y)
+	assert.Equal(t, []Rect{{Point{100, 0}, Point{300, 300}}}, c.Rect)
+	rows, err := r.Page(2).GetTextByRow()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, len(rows))
+	assert.Equal(t, "Out", rows[0].Content[0].S)
+	assert.Equal(t, 300.0, rows[1].Position)
+}
+
+func mustPlainText(t *testing.T, p Page) string {
+	s, err := p.GetPlainText(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
@@ -0,0 +1,272 @@
+// Copyright 2014 The Go Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Encodings of simple fonts, which map one-byte character codes to glyph
+// names and, by way of the Adobe Glyph List, to Unicode text.
+// See PDF 32000-1:2008, §9.6.6 and §9.10.2.
+
+package pdf
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// A simpleEncoding is the resolved encoding of a simple font:
+// the glyph name and Unicode text selected by each one-byte code.
+type simpleEncoding struct {
+	names [256]string
+	runes [256]rune       // noRune where the code has no known text
+	text  map[byte]string // codes whose glyph stands for several characters, such as f_f_i
+}
+
+func (e *simpleEncoding) Decode(raw string) (text string) {
+	r := make([]rune, 0, len(raw))
+	for i := 0; i < len(raw); i++ {
+		if s, ok := e.text[raw[i]]; ok {
+			r = append(r, []rune(s)...)
+			continue
+		}
+		r = append(r, e.runes[raw[i]])
+	}
+	return string(r)
+}
+
+// set records s as the text of code.
+func (e *simpleEncoding) set(code int, s string) {
+	r := []rune(s)
+	switch len(r) {
+	case 0:
+		return
+	case 1:
+		e.runes[code] = r[0]
+	default:
+		if e.text == nil {
+			e.text = make(map[byte]string)
+		}
+		e.text[byte(code)] = s
+	}
+}
+
+// simpleEncoding returns the encoding of the simple font f.
+func (f Font) simpleEncoding() *simpleEncoding {
+	if f.cache == nil {
+		return f.buildSimpleEncoding()
+	}
+	if f.cache.simple == nil {
+		f.cache.simple = f.buildSimpleEncoding()
+	}
+	return f.cache.simple
+}
+
+// buildSimpleEncoding builds the encoding of f. It starts from the base
+// encoding named by the font's Encoding entry, or else from the font's
+// built-in encoding, and applies the Differences array on top.
+// Glyph names are then turned into text using the Adobe Glyph List,
+// falling back to the glyph's Unicode value in the embedded font program.
+func (f Font) buildSimpleEncoding() *simpleEncoding {
+	e := new(simpleEncoding)
+	for i := range e.runes {
+		e.runes[i] = noRune
+	}
+	m := f.standardFont()
+	p := f.program()
+
+	enc := f.V.Key("Encoding")
+	base := enc
+	if enc.Kind() == Dict {
+		base = enc.Key("BaseEncoding")
+	}
+	switch base.Name() {
+	case "WinAnsiEncoding":
+		e.names, e.runes = winAnsiEncodingNames, winAnsiEncoding
+	case "MacRomanEncoding":
+		e.names, e.runes = macRomanEncodingNames, macRomanEncoding
+	case "MacExpertEncoding":
+		e.runes = macExpertEncoding
+	case "StandardEncoding":
+		e.names = standardEncodingNames
+	default:
+		f.builtinEncoding(e, m, p)
+	}
+
+	diff := enc.Key("Differences")
+	code := -1
+	for i := 0; i < diff.Len(); i++ {
+		x := diff.Index(i)
+		if x.Kind() == Integer {
+			code = int(x.Int64())
+			continue
+		}
+		if x.Kind() == Name && 0 <= code && code < 256 {
+			e.names[code] = x.Name()
+			e.runes[code] = noRune
+			delete(e.text, byte(code))
+		}
+		code++
+	}
+
+	dingbats := m != nil && m == stdFonts["ZapfDingbats"]
+	for code, name := range e.names {
+		if name != "" && e.runes[code] == noRune && e.text[byte(code)] == "" {
+			e.set(code, glyphText(name, dingbats, p))
+		}
+	}
+
+	// The glyph names of Type3 fonts are often just procedure names,
+	// such as a65 or g7 in fonts written by dvips. Where such a name
+	// means nothing, the code itself is the best guess at the text.
+	if f.Subtype() == "Type3" {
+		for code, name := range e.names {
+			if name != "" && e.runes[code] == noRune && e.text[byte(code)] == "" && unicode.IsGraphic(rune(code)) {
+				e.runes[code] = rune(code)
+			}
+		}
+	}
+	return e
+}
+
+// builtinEncoding fills e with the font's built-in encoding, used when
+// its Encoding entry names no base encoding. That is the encoding of the
+// embedded font program if it has one, the encoding of the standard font
+// m if f is one, or else StandardEncoding. A symbolic TrueType program has
+// no encoding as such; its codes select glyphs through the cmap table.
+func (f Font) builtinEncoding(e *simpleEncoding, m *stdFont, p *fontProgram) {
+	switch {
+	case p != nil && len(p.encoding) > 0:
+		for code, name := range p.encoding {
+			if 0 <= code && code < 256 {
+				e.names[code] = name
+			}
+		}
+		return
+	case m != nil:
+		e.names = *m.encoding
+		return
+	case p != nil:
+		found := false
+		for code := 0; code < 256; code++ {
+			if gid, ok := p.trueTypeGlyph(code); ok {
+				if gid < len(p.names) {
+					e.names[code] = p.names[gid]
+				}
+				e.set(code, p.glyphText(gid))
+				found = true
+			}
+		}
+		if found {
+			return
+		}
+	}
+	e.names = standardEncodingNames
+}
+
+// glyphText returns the Unicode text for the named glyph, from the
+// Adobe Glyph List or else from the font program p, which may be nil.
+// It returns "" if neither knows the glyph.
+func glyphText(name string, dingbats bool, p *fontProgram) string {
+	if s := glyphNameText(name, dingbats); s != "" {
+		return s
+	}
+	if p != nil {
+		if gid, ok := p.glyphIndex(name); ok {
+			return p.glyphText(gid)
+		}
+	}
+	return ""
+}
+
+// glyphNameText maps a glyph name to Unicode text following the Adobe
+// Glyph List Specification. Anything after the first period is dropped,
+// the rest is split into components at underscores, and each component
+// is looked up in the glyph list (or, if dingbats is set, first in the
+// ITC Zapf Dingbats list) or else read as a uniXXXX sequence or a
+// uXXXX[XX] value. Components that match none of these map to nothing.
+func glyphNameText(name string, dingbats bool) string {
+	if i := strings.Index(name, "."); i >= 0 {
+		name = name[:i]
+	}
+	var r []rune
+	for _, c := range strings.Split(name, "_") {
+		r = append(r, glyphComponentRunes(c, dingbats)...)
+	}
+	return string(r)
+}
+
+func glyphComponentRunes(c string, dingbats bool) []rune {
+	if dingbats {
+		if r, ok := dingbatsNameToRune[c]; ok {
+			return []rune{r}
+		}
+	}
+	if r, ok := nameToRune[c]; ok {
+		return []rune{r}
+	}
+	if strings.HasPrefix(c, "uni") && len(c) > 3 && (len(c)-3)%4 == 0 {
+		var r []rune
+		for i := 3; i < len(c); i += 4 {
+			x, ok := glyphNameHex(c[i : i+4])
+			if !ok {
+				return nil
+			}
+			r = append(r, x)
+		}
+		return r
+	}
+	if strings.HasPrefix(c, "u") && 5 <= len(c) && len(c) <= 7 {
+		if x, ok := glyphNameHex(c[1:]); ok {
+			return []rune{x}
+		}
+	}
+	return nil
+}
+
+// glyphNameHex parses the uppercase hexadecimal digits s, as used in uniXXXX
+// and uXXXX glyph names, rejecting surrogates and values beyond Unicode.
+func glyphNameHex(s string) (rune, bool) {
+	for i := 0; i < len(s); i++ {
+		if !('0' <= s[i] && s[i] <= '9' || 'A' <= s[i] && s[i] <= 'F') {
+			return 0, false
+		}
+	}
+	x, err := strconv.ParseUint(s, 16, 32)
+	if err != nil || 0xD800 <= x && x <= 0xDFFF || x > 0x10FFFF {
+		return 0, false
+	}
+	return rune(x), true
+}
+
+// Glyph names for the WinAnsi and MacRoman encodings, used to look up
+// the widths of standard fonts, and the glyph names of the ITC Zapf
+// Dingbats list, which are not part of the Adobe Glyph List.
+var (
+	winAnsiEncodingNames  [256]string
+	macRomanEncodingNames [256]string
+	dingbatsNameToRune    = make(map[string]rune)
+)
+
+func init() {
+	latin := make(map[rune]string)
+	var names []string
+	for name := range stdFonts["Times-Roman"].widths {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if r, ok := nameToRune[name]; ok {
+			latin[r] = name
+		}
+	}
+	latin[0x00A0] = "space" // the encodings' nonbreaking space and soft hyphen
+	latin[0x00AD] = "hyphen"
+	for code := 0; code < 256; code++ {
+		winAnsiEncodingNames[code] = latin[winAnsiEncoding[code]]
+		macRomanEncodingNames[code] = latin[macRomanEncoding[code]]
+		if name := zapfDingbatsEncodingNames[code]; name != "" {
+			dingbatsNameToRune[name] = zapfDingbatsEncoding[code]
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package pdf
+
+import (
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+func TestGlyphNameText(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		text string
+	}{
+		{"A", "A"},
+		{"Euro", "€"},
+		{"uni20AC", "€"},
+		{"uni00410042", "AB"},
+		{"u1F600", "\U0001F600"},
+		{"f_f_i", "ffi"},
+		{"f_f_i.liga", "ffi"},
+		{"a.sc", "a"},
+		{"uniD800", ""},
+		{"uni20ac", ""},
+		{"u110000", ""},
+		{".notdef", ""},
+		{"g123", ""},
+	} {
+		assert.Equal(t, tt.text, glyphNameText(tt.name, false), tt.name)
+	}
+	assert.Equal(t, "✁", glyphNameText("a1", true))
+}
+
+func TestSimpleEncoding(t *testing.T) {
+	font := Font{V: Value{data: dict{
+		"Subtype":  name("Type1"),
+		"BaseFont": name("ABCDEF+Minion"),
+		"Encoding": dict{
+			"BaseEncoding": name("MacRomanEncoding"),
+			"Differences":  array{int64(1), name("f_f_i"), name("uni2022"), int64(0x41), name("Omega")},
+		},
+	}}}
+	assert.Equal(t, "ffi•ΩBé", font.Encoder().Decode("\x01\x02AB\x8e"))
+
+	standard := Font{V: Value{data: dict{
+		"Subtype":  name("Type1"),
+		"BaseFont": name("ABCDEF+Minion"),
+		"Encoding": dict{"Differences": array{int64(0x80), name("Euro")}},
+	}}}
+	assert.Equal(t, "’a€�", standard.Encoder().Decode("'a\x80\x81"))
+
+	symbol := Font{V: Value{data: dict{"Subtype": name("Type1"), "BaseFont": name("Symbol")}}}
+	assert.Equal(t, "αβ", symbol.Encoder().Decode("ab"))
+
+	dingbats := Font{V: Value{data: dict{"Subtype": name("Type1"), "BaseFont": name("ZapfDingbats")}}}
+	assert.Equal(t, "✁★", dingbats.Encoder().Decode("!H"))
+}
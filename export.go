@@ -0,0 +1,314 @@
+// Copyright 2014 The Go Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Export of the text of a document, with its geometry,
+// as JSON, hOCR, ALTO and positioned HTML.
+
+package pdf
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"math"
+	"strconv"
+)
+
+// A Document is the text of a document's pages arranged into blocks,
+// lines and words, with their bounding boxes: the model that the
+// exporters write.
+//
+// Coordinates have their origin at the top left corner of the page, with Y
+// increasing down the page, as the export formats expect. They are in
+// points, unless the extraction options select display space with a DPI,
+// in which case they are in display space. Only display space turns
+// pages by their Rotate.
+type Document struct {
+	DPI   float64 // the resolution of the coordinates, in units per inch
+	Pages []PageText
+}
+
+// A PageText is the text of a page.
+type PageText struct {
+	Number        int // the number of the page, starting at 1
+	Width, Height float64
+	Blocks        []TextBlock
+}
+
+// A TextBlock is a group of lines set together, such as a paragraph.
+type TextBlock struct {
+	Rect  Rect
+	Lines []TextLine
+}
+
+// A TextLine is a line of words, in reading order.
+type TextLine struct {
+	Rect  Rect
+	Words []TextWord
+}
+
+// A TextWord is a word and its font.
+type TextWord struct {
+	Text     string
+	Font     string
+	FontSize float64
+	Rect     Rect
+}
+
+// Document returns the text of the document's pages, arranged by Page.Layout.
+// It lays out every page before it returns, so it holds the text of the
+// whole document in memory; to handle one page at a time, call Page.Layout
+// from WalkPages.
+func (r *Reader) Document() (Document, error) {
+	doc := Document{DPI: 72}
+	opts := r.extractOptions()
+	if opts.DisplaySpace && opts.DPI > 0 {
+		doc.DPI = opts.DPI
+	}
+	err := r.WalkPages(func(i int, p Page) error {
+		page, err := exportPage(p.WithExtractOptions(opts), i)
+		if err != nil {
+			return fmt.Errorf("page %d: %v", i, err)
+		}
+		doc.Pages = append(doc.Pages, page)
+		return nil
+	})
+	if err != nil {
+		return Document{}, err
+	}
+	return doc, nil
+}
+
+// exportPage returns the text of p, the page with the given number,
+// recovering from the panics of a malformed content stream.
+func exportPage(p Page, number int) (page PageText, err error) {
+	p = p.fixOptions()
+	defer func() {
+		if r := recover(); r != nil {
+			page = PageText{}
+			err = errors.New(fmt.Sprint(r))
+		}
+	}()
+
+	// Flip user space to put the origin at the top left of the crop box.
+	top := func(r Rect) Rect { return r }
+	box := p.uprightBox()
+	if p.display() == nil {
+		top = func(r Rect) Rect {
+			return Rect{Point{r.Min.X - box.Min.X, box.Max.Y - r.Max.Y}, Point{r.Max.X - box.Min.X, box.Max.Y - r.Min.Y}}
+		}
+	}
+
+	page = PageText{Number: number, Width: box.Max.X - box.Min.X, Height: box.Max.Y - box.Min.Y}
+	for _, b := range p.Layout().Blocks {
+		block := TextBlock{Rect: top(b.Rect)}
+		for _, l := range b.Lines {
+			line := TextLine{Rect: top(l.Rect)}
+			for _, w := range l.Words {
+				line.Words = append(line.Words, TextWord{w.S, w.Font, w.FontSize, top(w.Rect)})
+			}
+			block.Lines = append(block.Lines, line)
+		}
+		page.Blocks = append(page.Blocks, block)
+	}
+	return page, nil
+}
+
+// WriteJSON writes the document to w as a JSON object.
+func (doc Document) WriteJSON(w io.Writer) error {
+	return json.NewEncoder(w).Encode(doc)
+}
+
+// WriteHOCR writes the document to w as hOCR, the XHTML format for
+// OCR results, with a div of class ocr_page for each page.
+// Bounding boxes are rounded to whole units.
+func (doc Document) WriteHOCR(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html PUBLIC "-//W3C//DTD XHTML 1.0 Transitional//EN" "http://www.w3.org/TR/xhtml1/DTD/xhtml1-transitional.dtd">
+<html xmlns="http://www.w3.org/1999/xhtml">
+<head>
+<title></title>
+<meta http-equiv="Content-Type" content="text/html; charset=utf-8" />
+<meta name="ocr-system" content="github.com/shouldend/pdf" />
+<meta name="ocr-capabilities" content="ocr_page ocr_carea ocr_par ocr_line ocrx_word" />
+</head>
+<body>
+`)
+	bbox := func(r Rect) string {
+		return fmt.Sprintf("bbox %d %d %d %d", round(r.Min.X), round(r.Min.Y), round(r.Max.X), round(r.Max.Y))
+	}
+	for _, p := range doc.Pages {
+		n := p.Number
+		fmt.Fprintf(bw, "<div class=\"ocr_page\" id=\"page_%d\" title=\"%s; ppageno %d; scan_res %d %d\">\n",
+			n, bbox(Rect{Max: Point{p.Width, p.Height}}), n-1, round(doc.DPI), round(doc.DPI))
+		var lines, words int
+		for i, b := range p.Blocks {
+			fmt.Fprintf(bw, "<div class=\"ocr_carea\" id=\"block_%d_%d\" title=\"%s\">\n", n, i+1, bbox(b.Rect))
+			fmt.Fprintf(bw, "<p class=\"ocr_par\" id=\"par_%d_%d\" title=\"%s\">\n", n, i+1, bbox(b.Rect))
+			for _, l := range b.Lines {
+				lines++
+				fmt.Fprintf(bw, "<span class=\"ocr_line\" id=\"line_%d_%d\" title=\"%s\">", n, lines, bbox(l.Rect))
+				for j, wd := range l.Words {
+					words++
+					if j > 0 {
+						bw.WriteString(" ")
+					}
+					fmt.Fprintf(bw, "<span class=\"ocrx_word\" id=\"word_%d_%d\" title=\"%s; x_font %s; x_fsize %s\">%s</span>",
+						n, words, bbox(wd.Rect), html.EscapeString(quoteFont(wd.Font)), num(wd.FontSize), html.EscapeString(wd.Text))
+				}
+				bw.WriteString("</span>\n")
+			}
+			bw.WriteString("</p>\n</div>\n")
+		}
+		bw.WriteString("</div>\n")
+	}
+	bw.WriteString("</body>\n</html>\n")
+	return bw.Flush()
+}
+
+// quoteFont quotes a font name for an hOCR property if it contains spaces.
+func quoteFont(name string) string {
+	for _, c := range name {
+		if c == ' ' || c == ';' || c == '"' {
+			return strconv.Quote(name)
+		}
+	}
+	return name
+}
+
+// WriteALTO writes the document to w as ALTO version 4 XML, the format
+// of the Library of Congress for the layout of digitized text. Positions
+// are measured in 1/1200 inch; font sizes, as ALTO requires, in points.
+func (doc Document) WriteALTO(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	unit := 1200 / doc.DPI
+	pos := func(r Rect) string {
+		return fmt.Sprintf(`HPOS="%s" VPOS="%s" WIDTH="%s" HEIGHT="%s"`,
+			num(r.Min.X*unit), num(r.Min.Y*unit), num((r.Max.X-r.Min.X)*unit), num((r.Max.Y-r.Min.Y)*unit))
+	}
+
+	// Gather the text styles: each font at each size.
+	type style struct {
+		font string
+		size string
+	}
+	styles := make(map[style]int)
+	var order []style
+	for _, p := range doc.Pages {
+		for _, b := range p.Blocks {
+			for _, l := range b.Lines {
+				for _, wd := range l.Words {
+					s := style{wd.Font, num(wd.FontSize * 72 / doc.DPI)}
+					if _, ok := styles[s]; !ok {
+						styles[s] = len(order)
+						order = append(order, s)
+					}
+				}
+			}
+		}
+	}
+
+	bw.WriteString(`<?xml version="1.0" encoding="UTF-8"?>
+<alto xmlns="http://www.loc.gov/standards/alto/ns-v4#" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:schemaLocation="http://www.loc.gov/standards/alto/ns-v4# http://www.loc.gov/alto/v4/alto-4-2.xsd">
+<Description>
+<MeasurementUnit>inch1200</MeasurementUnit>
+</Description>
+<Styles>
+`)
+	for i, s := range order {
+		fmt.Fprintf(bw, "<TextStyle ID=\"font%d\" FONTFAMILY=\"%s\" FONTSIZE=\"%s\"/>\n", i, html.EscapeString(s.font), s.size)
+	}
+	bw.WriteString("</Styles>\n<Layout>\n")
+	for _, p := range doc.Pages {
+		n := p.Number
+		page := Rect{Max: Point{p.Width, p.Height}}
+		fmt.Fprintf(bw, "<Page ID=\"page_%d\" PHYSICAL_IMG_NR=\"%d\" WIDTH=\"%s\" HEIGHT=\"%s\">\n", n, n, num(p.Width*unit), num(p.Height*unit))
+		fmt.Fprintf(bw, "<PrintSpace %s>\n", pos(page))
+		var lines, words int
+		for i, b := range p.Blocks {
+			fmt.Fprintf(bw, "<TextBlock ID=\"block_%d_%d\" %s>\n", n, i+1, pos(b.Rect))
+			for _, l := range b.Lines {
+				lines++
+				fmt.Fprintf(bw, "<TextLine ID=\"line_%d_%d\" %s>\n", n, lines, pos(l.Rect))
+				for j, wd := range l.Words {
+					words++
+					if j > 0 {
+						bw.WriteString("<SP/>\n")
+					}
+					s := style{wd.Font, num(wd.FontSize * 72 / doc.DPI)}
+					fmt.Fprintf(bw, "<String ID=\"word_%d_%d\" %s CONTENT=\"%s\" STYLEREFS=\"font%d\"/>\n",
+						n, words, pos(wd.Rect), html.EscapeString(wd.Text), styles[s])
+				}
+				bw.WriteString("</TextLine>\n")
+			}
+			bw.WriteString("</TextBlock>\n")
+		}
+		bw.WriteString("</PrintSpace>\n</Page>\n")
+	}
+	bw.WriteString("</Layout>\n</alto>\n")
+	return bw.Flush()
+}
+
+// WriteHTML writes the document to w as an HTML page in which each word
+// is placed where it is on its page, with a fixed-size div for each page.
+// One unit of the coordinates becomes one CSS pixel.
+func (doc Document) WriteHTML(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title></title>
+<style>
+.page { position: relative; overflow: hidden; margin: 1em auto; border: 1px solid #ccc; }
+.page span { position: absolute; white-space: pre; line-height: 1; }
+</style>
+</head>
+<body>
+`)
+	for _, p := range doc.Pages {
+		fmt.Fprintf(bw, "<div class=\"page\" id=\"page-%d\" style=\"width: %spx; height: %spx\">\n", p.Number, num(p.Width), num(p.Height))
+		for _, b := range p.Blocks {
+			for _, l := range b.Lines {
+				for _, wd := range l.Words {
+					fmt.Fprintf(bw, "<span style=\"left: %spx; top: %spx; width: %spx; height: %spx; font-size: %spx; font-family: %s\">%s</span>\n",
+						num(wd.Rect.Min.X), num(wd.Rect.Min.Y), num(wd.Rect.Max.X-wd.Rect.Min.X), num(wd.Rect.Max.Y-wd.Rect.Min.Y),
+						num(wd.FontSize), html.EscapeString(cssString(wd.Font)), html.EscapeString(wd.Text))
+				}
+			}
+		}
+		bw.WriteString("</div>\n")
+	}
+	bw.WriteString("</body>\n</html>\n")
+	return bw.Flush()
+}
+
+// cssString quotes s as a CSS string. Quotes, backslashes and control
+// characters are written as CSS escapes: a backslash, the hexadecimal
+// code point and a space.
+func cssString(s string) string {
+	b := []byte{'"'}
+	for _, c := range s {
+		if c == '"' || c == '\\' || c < ' ' || c == 0x7F {
+			b = append(b, fmt.Sprintf("\\%x ", c)...)
+		} else {
+			b = append(b, string(c)...)
+		}
+	}
+	return string(append(b, '"'))
+}
+
+// round rounds v to the nearest integer.
+func round(v float64) int {
+	return int(math.Floor(v + 0.5))
+}
+
+// num formats v with at most two decimal places.
+func num(v float64) string {
+	return strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64)
+}
@@ -0,0 +1,65 @@
+package pdf
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+func TestExport(t *testing.T) {
+	r := newTestPDF(t,
+		`<< /Type /Catalog /Pages 2 0 R >>`,
+		`<< /Type /Pages /Kids [3 0 R] /Count 1 >>`,
+		`<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 4 0 R
+		   /Resources << /Font << /F1 << /Type /Font /Subtype /Type1 /BaseFont /Helvetica >> >> >> >>`,
+		`stream
+BT /F1 12 Tf 1 0 0 1 72 700 Tm (Fish & Chips) Tj 1 0 0 1 72 686 Tm (<served>) Tj ET`,
+	)
+	doc, err := r.Document()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, len(doc.Pages))
+	p := doc.Pages[0]
+	assert.Equal(t, 612.0, p.Width)
+	assert.Equal(t, 792.0, p.Height)
+	assert.Equal(t, 1, len(p.Blocks))
+	assert.Equal(t, 2, len(p.Blocks[0].Lines))
+	w := p.Blocks[0].Lines[0].Words[0]
+	assert.Equal(t, "Fish", w.Text)
+	assert.Equal(t, "Helvetica", w.Font)
+	assert.Equal(t, 72.0, w.Rect.Min.X)
+	assert.T(t, w.Rect.Min.Y < 92 && w.Rect.Max.Y > 92, w.Rect) // the baseline is 92 from the top
+
+	var buf bytes.Buffer
+	assert.Equal(t, nil, doc.WriteJSON(&buf))
+	var back Document
+	assert.Equal(t, nil, json.Unmarshal(buf.Bytes(), &back))
+	assert.Equal(t, doc, back)
+
+	buf.Reset()
+	assert.Equal(t, nil, doc.WriteHOCR(&buf))
+	s := buf.String()
+	assert.T(t, strings.Contains(s, `<meta name="ocr-system" content="github.com/shouldend/pdf" />`), s)
+	assert.T(t, strings.Contains(s, `<div class="ocr_page" id="page_1" title="bbox 0 0 612 792; ppageno 0; scan_res 72 72">`), s)
+	assert.T(t, strings.Contains(s, `x_font Helvetica; x_fsize 12">&amp;</span>`), s)
+	assert.T(t, strings.Contains(s, `&lt;served&gt;</span></span>`), s)
+
+	buf.Reset()
+	assert.Equal(t, nil, doc.WriteALTO(&buf))
+	s = buf.String()
+	assert.T(t, strings.Contains(s, `<TextStyle ID="font0" FONTFAMILY="Helvetica" FONTSIZE="12"/>`), s)
+	assert.T(t, strings.Contains(s, `<Page ID="page_1" PHYSICAL_IMG_NR="1" WIDTH="10200" HEIGHT="13200">`), s)
+	assert.T(t, strings.Contains(s, `HPOS="1200" `), s)
+	assert.T(t, strings.Contains(s, `CONTENT="Chips" STYLEREFS="font0"/>`), s)
+
+	buf.Reset()
+	assert.Equal(t, nil, doc.WriteHTML(&buf))
+	s = buf.String()
+	assert.T(t, strings.Contains(s, `<div class="page" id="page-1" style="width: 612px; height: 792px">`), s)
+	assert.T(t, strings.Contains(s, `<span style="left: 72px; `), s)
+	assert.T(t, strings.Contains(s, `font-family: &#34;Helvetica&#34;">Fish</span>`), s)
+
+	assert.Equal(t, `"Caf\22 \5c é"`, cssString(`Caf"\é`))
+}
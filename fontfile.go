@@ -0,0 +1,793 @@
+// Copyright 2014 The Go Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Reading of embedded font programs (FontFile, FontFile2 and FontFile3 streams),
+// used to recover Unicode text for fonts that have no ToUnicode CMap.
+
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// A fontProgram holds the parts of an embedded font program that
+// relate character codes and glyphs to glyph names and Unicode.
+type fontProgram struct {
+	encoding map[int]string         // built-in encoding (Type1, CFF): code -> glyph name
+	names    []string               // glyph names indexed by glyph index (post table, CFF charset)
+	cmaps    map[[2]int]map[int]int // TrueType cmap subtables: {platform, encoding} -> code -> glyph index
+	unicode  map[int]rune           // glyph index -> Unicode, from a Unicode cmap subtable
+	cids     []int                  // glyph index -> CID, in a CID-keyed CFF font, which has no glyph names
+}
+
+// program returns the font program embedded in f,
+// or nil if there is none or it cannot be parsed.
+func (f Font) program() *fontProgram {
+	desc := f.V.Key("FontDescriptor")
+	if f.Subtype() == "Type0" {
+		desc = f.descendant().Key("FontDescriptor")
+	}
+	for _, key := range []string{"FontFile", "FontFile2", "FontFile3"} {
+		if strm := desc.Key(key); strm.Kind() == Stream {
+			return f.V.r.fontProgram(key, strm)
+		}
+	}
+	return nil
+}
+
+// maxFontPrograms is the number of parsed font programs a Reader keeps.
+// Pages rarely use more fonts than this, and a document using many more
+// would otherwise keep all of them in memory for the Reader's lifetime.
+const maxFontPrograms = 32
+
+// fontProgram returns the parsed font program in strm, the value of
+// the font descriptor's key entry. Results are cached by stream object,
+// since the same program is consulted every time its font is selected;
+// the least recently used are dropped once there are maxFontPrograms.
+func (r *Reader) fontProgram(key string, strm Value) *fontProgram {
+	if r == nil {
+		return parseFontProgram(key, strm)
+	}
+	ptr := strm.data.(stream).ptr
+	r.fontMu.Lock()
+	defer r.fontMu.Unlock()
+	if p, ok := r.fontPrograms[ptr]; ok {
+		r.useFontProgram(ptr)
+		return p
+	}
+	p := parseFontProgram(key, strm)
+	if r.fontPrograms == nil {
+		r.fontPrograms = make(map[objptr]*fontProgram)
+	}
+	if len(r.fontOrder) >= maxFontPrograms {
+		delete(r.fontPrograms, r.fontOrder[0])
+		r.fontOrder = append(r.fontOrder[:0], r.fontOrder[1:]...)
+	}
+	r.fontPrograms[ptr] = p
+	r.fontOrder = append(r.fontOrder, ptr)
+	return p
+}
+
+// useFontProgram moves the cached program of stream ptr to the
+// most recently used end of r.fontOrder.
+func (r *Reader) useFontProgram(ptr objptr) {
+	for i, q := range r.fontOrder {
+		if q == ptr {
+			copy(r.fontOrder[i:], r.fontOrder[i+1:])
+			r.fontOrder[len(r.fontOrder)-1] = ptr
+			return
+		}
+	}
+}
+
+func parseFontProgram(key string, strm Value) (p *fontProgram) {
+	defer func() {
+		if recover() != nil {
+			p = nil
+		}
+	}()
+	data, err := ioutil.ReadAll(strm.Reader())
+	if err != nil {
+		return nil
+	}
+	switch key {
+	case "FontFile":
+		return parseType1(data, int(strm.Key("Length1").Int64()))
+	case "FontFile2":
+		return parseSFNT(fontData(data))
+	case "FontFile3":
+		switch strm.Key("Subtype").Name() {
+		case "Type1C", "CIDFontType0C":
+			return parseCFF(fontData(data))
+		case "OpenType":
+			return parseSFNT(fontData(data))
+		}
+	}
+	return nil
+}
+
+// glyphText returns the Unicode text of the glyph with index gid,
+// taken from a Unicode cmap subtable or else from the glyph's name.
+func (p *fontProgram) glyphText(gid int) string {
+	if r, ok := p.unicode[gid]; ok {
+		return string(r)
+	}
+	if 0 <= gid && gid < len(p.names) {
+		return glyphNameText(p.names[gid], false)
+	}
+	return ""
+}
+
+// glyphIndex returns the index of the named glyph. Besides the names
+// in the program's charset or post table, it accepts names of the form
+// gNNN, which some producers use for the glyph with index NNN.
+func (p *fontProgram) glyphIndex(name string) (int, bool) {
+	for gid, n := range p.names {
+		if n == name {
+			return gid, true
+		}
+	}
+	if len(name) > 1 && name[0] == 'g' && strings.Trim(name[1:], "0123456789") == "" {
+		if gid, err := strconv.Atoi(name[1:]); err == nil {
+			return gid, true
+		}
+	}
+	return 0, false
+}
+
+// trueTypeGlyph returns the glyph index selected by code in a symbolic
+// TrueType font, using the (3,0) or (1,0) cmap subtable as described
+// in PDF 32000-1:2008, §9.6.6.4.
+func (p *fontProgram) trueTypeGlyph(code int) (int, bool) {
+	if m := p.cmaps[[2]int{3, 0}]; m != nil {
+		for _, c := range []int{code, 0xF000 | code, 0xF100 | code, 0xF200 | code} {
+			if gid, ok := m[c]; ok && gid != 0 {
+				return gid, true
+			}
+		}
+	}
+	if m := p.cmaps[[2]int{1, 0}]; m != nil {
+		if gid, ok := m[code]; ok && gid != 0 {
+			return gid, true
+		}
+	}
+	return 0, false
+}
+
+// programEncoding returns an encoder that decodes the character codes of
+// the Type0 font f by way of its embedded font program, or nil if the
+// program yields no Unicode mapping. Codes the program cannot map are
+// passed to fallback. Simple fonts consult their programs while building
+// their encodings instead; see simpleEncoding.
+//
+// A CID-keyed CFF font names no glyphs, so only one wrapped in OpenType,
+// with a Unicode cmap subtable, yields a mapping; a bare CIDFontType0C
+// program would need the Unicode mappings of its character collection,
+// such as Adobe-Japan1, which are not built in.
+func (f Font) programEncoding(fallback TextEncoding) TextEncoding {
+	p := f.program()
+	if p == nil || f.Subtype() != "Type0" {
+		return nil
+	}
+	e := &programEncoder{table: make(map[int]string), fallback: fallback}
+	e.cids = f.encodingCMap()
+	e.space = f.codespace(e.cids)
+	cidToGID := f.descendant().Key("CIDToGIDMap")
+	if p.cids != nil { // the charset maps glyphs to CIDs
+		for gid, cid := range p.cids {
+			if s := p.glyphText(gid); s != "" {
+				if _, ok := e.table[cid]; !ok {
+					e.table[cid] = s
+				}
+			}
+		}
+	} else if cidToGID.Kind() == Stream {
+		data, err := ioutil.ReadAll(cidToGID.Reader())
+		if err != nil {
+			return nil
+		}
+		for cid := 0; 2*cid+1 < len(data); cid++ {
+			if s := p.glyphText(int(data[2*cid])<<8 | int(data[2*cid+1])); s != "" {
+				e.table[cid] = s
+			}
+		}
+	} else {
+		n := len(p.names)
+		for gid := range p.unicode {
+			if gid >= n {
+				n = gid + 1
+			}
+		}
+		for gid := 0; gid < n; gid++ {
+			if s := p.glyphText(gid); s != "" {
+				e.table[gid] = s
+			}
+		}
+	}
+	if len(e.table) == 0 {
+		return nil
+	}
+	return e
+}
+
+// A programEncoder decodes character codes using the
+// glyph names and cmap tables of an embedded font program.
+type programEncoder struct {
+	space    *codespace
+	cids     *cmap // maps codes to CIDs in a Type0 font; nil means identity
+	table    map[int]string
+	fallback TextEncoding
+}
+
+func (e *programEncoder) Decode(raw string) (text string) {
+	var r []rune
+	for _, code := range e.space.split(raw) {
+		c := codeInt(code)
+		if e.cids != nil {
+			c = e.cids.cid(code)
+		}
+		if s, ok := e.table[c]; ok {
+			r = append(r, []rune(s)...)
+			continue
+		}
+		r = append(r, []rune(e.fallback.Decode(code))...)
+	}
+	return string(r)
+}
+
+// fontData is the raw data of a font program.
+// Like the Value accessors, its methods return zero
+// for reads that fall outside the data.
+type fontData []byte
+
+func (d fontData) u8(off int) int {
+	if off < 0 || off >= len(d) {
+		return 0
+	}
+	return int(d[off])
+}
+
+func (d fontData) u16(off int) int {
+	return d.u8(off)<<8 | d.u8(off+1)
+}
+
+func (d fontData) i16(off int) int {
+	return int(int16(d.u16(off)))
+}
+
+func (d fontData) u32(off int) int {
+	return d.u16(off)<<16 | d.u16(off+2)
+}
+
+// slice returns the n bytes at off, clipped to the data.
+func (d fontData) slice(off, n int) fontData {
+	if off < 0 || off > len(d) || n < 0 {
+		return nil
+	}
+	if n > len(d)-off {
+		n = len(d) - off
+	}
+	return d[off : off+n]
+}
+
+// parseSFNT parses a TrueType or OpenType font.
+// See the OpenType specification, https://docs.microsoft.com/typography/opentype/spec/.
+func parseSFNT(d fontData) *fontProgram {
+	tables := make(map[string]fontData)
+	n := d.u16(4)
+	for i := 0; i < n; i++ {
+		rec := 12 + 16*i
+		tables[string(d.slice(rec, 4))] = d.slice(d.u32(rec+8), d.u32(rec+12))
+	}
+	p := &fontProgram{}
+	if cff, ok := tables["CFF "]; ok {
+		if q := parseCFF(cff); q != nil {
+			p = q
+		}
+	}
+	p.cmaps = parseCmapTable(tables["cmap"])
+	p.unicode = make(map[int]rune)
+	for _, id := range [][2]int{{3, 10}, {0, 4}, {3, 1}, {0, 3}} {
+		for code, gid := range p.cmaps[id] {
+			if r, ok := p.unicode[gid]; !ok || rune(code) < r {
+				p.unicode[gid] = rune(code)
+			}
+		}
+	}
+	if p.names == nil {
+		p.names = parsePost(tables["post"])
+	}
+	return p
+}
+
+// maxCmapCodes limits the number of codes that the subtables of a cmap
+// table may map in all, so that a malformed table with huge or many
+// overlapping ranges cannot take unbounded time and memory. It is the
+// number of Unicode code points.
+const maxCmapCodes = 0x110000
+
+// parseCmapTable parses the subtables of a TrueType cmap table
+// in formats 0, 4, 6 and 12, the ones found in fonts embedded in PDF files.
+func parseCmapTable(d fontData) map[[2]int]map[int]int {
+	cmaps := make(map[[2]int]map[int]int)
+	budget := maxCmapCodes // codes left to map
+	n := d.u16(2)
+	for i := 0; i < n; i++ {
+		rec := 4 + 8*i
+		id := [2]int{d.u16(rec), d.u16(rec + 2)}
+		if _, ok := cmaps[id]; ok {
+			continue
+		}
+		sub := d.slice(d.u32(rec+4), len(d))
+		m := make(map[int]int)
+		switch sub.u16(0) {
+		case 0:
+			for code := 0; code < 256 && budget > 0; code++ {
+				budget--
+				if gid := sub.u8(6 + code); gid != 0 {
+					m[code] = gid
+				}
+			}
+		case 4:
+			segs := sub.u16(6) / 2
+			ends, starts, deltas, offsets := 14, 16+2*segs, 16+4*segs, 16+6*segs
+			for s := 0; s < segs; s++ {
+				end, start := sub.u16(ends+2*s), sub.u16(starts+2*s)
+				delta, offset := sub.u16(deltas+2*s), sub.u16(offsets+2*s)
+				if start > end || end == 0xFFFF && start == 0xFFFF {
+					continue
+				}
+				for code := start; code <= end && budget > 0; code++ {
+					budget--
+					gid := 0
+					if offset == 0 {
+						gid = (code + delta) & 0xFFFF
+					} else if g := sub.u16(offsets + 2*s + offset + 2*(code-start)); g != 0 {
+						gid = (g + delta) & 0xFFFF
+					}
+					if gid != 0 {
+						m[code] = gid
+					}
+				}
+			}
+		case 6:
+			first, count := sub.u16(6), sub.u16(8)
+			for i := 0; i < count && budget > 0; i++ {
+				budget--
+				if gid := sub.u16(10 + 2*i); gid != 0 {
+					m[first+i] = gid
+				}
+			}
+		case 12:
+			groups := sub.u32(12)
+			for g := 0; g < groups && 16+12*g < len(sub) && budget > 0; g++ {
+				start, end, gid := sub.u32(16+12*g), sub.u32(20+12*g), sub.u32(24+12*g)
+				if start > end {
+					continue
+				}
+				for code := start; code <= end && code <= 0x10FFFF && budget > 0; code++ {
+					budget--
+					m[code] = gid + code - start
+				}
+			}
+		default:
+			continue
+		}
+		cmaps[id] = m
+	}
+	return cmaps
+}
+
+// parsePost returns the glyph names listed in a TrueType post table.
+func parsePost(d fontData) []string {
+	switch d.u32(0) {
+	case 0x00010000:
+		return macGlyphNames[:]
+	case 0x00020000:
+		n := d.u16(32)
+		var pascal []string
+		for off := 34 + 2*n; off < len(d); off += 1 + d.u8(off) {
+			pascal = append(pascal, string(d.slice(off+1, d.u8(off))))
+		}
+		names := make([]string, n)
+		for gid := range names {
+			i := d.u16(34 + 2*gid)
+			switch {
+			case i < len(macGlyphNames):
+				names[gid] = macGlyphNames[i]
+			case i-len(macGlyphNames) < len(pascal):
+				names[gid] = pascal[i-len(macGlyphNames)]
+			}
+		}
+		return names
+	}
+	return nil
+}
+
+// cffIndex returns the entries of the CFF INDEX at off
+// and the offset of the first byte after it.
+func cffIndex(d fontData, off int) ([]fontData, int) {
+	count := d.u16(off)
+	if count == 0 {
+		return nil, off + 2
+	}
+	size := d.u8(off + 2)
+	at := func(i int) int {
+		x := 0
+		for j := 0; j < size; j++ {
+			x = x<<8 | d.u8(off+3+i*size+j)
+		}
+		return x
+	}
+	base := off + 2 + (count+1)*size
+	var entries []fontData
+	for i := 0; i < count; i++ {
+		entries = append(entries, d.slice(base+at(i), at(i+1)-at(i)))
+	}
+	return entries, base + at(count)
+}
+
+// cffDict parses a CFF DICT into a map from operator to operands.
+// Two-byte operators 12 x are stored as 1200+x.
+func cffDict(d fontData) map[int][]float64 {
+	dict := make(map[int][]float64)
+	var operands []float64
+	for i := 0; i < len(d); {
+		b := int(d[i])
+		switch {
+		case b <= 21:
+			op := b
+			i++
+			if b == 12 {
+				op = 1200 + d.u8(i)
+				i++
+			}
+			dict[op] = operands
+			operands = nil
+		case b == 28:
+			operands = append(operands, float64(d.i16(i+1)))
+			i += 3
+		case b == 29:
+			operands = append(operands, float64(int32(d.u32(i+1))))
+			i += 5
+		case b == 30: // real number, skipped nibble by nibble
+			for i++; i < len(d) && d[i]&0x0F != 0x0F && d[i]&0xF0 != 0xF0; i++ {
+			}
+			i++
+			operands = append(operands, 0)
+		case 32 <= b && b <= 246:
+			operands = append(operands, float64(b-139))
+			i++
+		case 247 <= b && b <= 250:
+			operands = append(operands, float64((b-247)*256+d.u8(i+1)+108))
+			i += 2
+		case 251 <= b && b <= 254:
+			operands = append(operands, float64(-(b-251)*256-d.u8(i+1)-108))
+			i += 2
+		default:
+			i++
+		}
+	}
+	return dict
+}
+
+// parseCFF parses a Compact Font Format font program.
+// See Adobe Technical Note #5176, The Compact Font Format Specification.
+func parseCFF(d fontData) *fontProgram {
+	_, off := cffIndex(d, d.u8(2)) // Name INDEX
+	top, off := cffIndex(d, off)
+	strs, _ := cffIndex(d, off)
+	if len(top) == 0 {
+		return nil
+	}
+	dict := cffDict(top[0])
+	sid := func(s int) string {
+		if s < len(cffStandardStrings) {
+			return cffStandardStrings[s]
+		}
+		if s-len(cffStandardStrings) < len(strs) {
+			return string(strs[s-len(cffStandardStrings)])
+		}
+		return ""
+	}
+	arg := func(op int) int {
+		if v := dict[op]; len(v) > 0 {
+			return int(v[0])
+		}
+		return 0
+	}
+
+	p := &fontProgram{encoding: make(map[int]string)}
+	_, cidKeyed := dict[1230] // ROS
+	charStrings, _ := cffIndex(d, arg(17))
+	nGlyphs := len(charStrings)
+
+	// The charset lists the SID (or CID) of every glyph after .notdef.
+	sids := make([]int, nGlyphs)
+	switch cs := arg(15); cs {
+	case 0: // ISOAdobe
+		for gid := range sids {
+			sids[gid] = gid
+		}
+	case 1, 2: // Expert, ExpertSubset: no glyph names we can use
+	default:
+		switch d.u8(cs) {
+		case 0:
+			for gid := 1; gid < nGlyphs; gid++ {
+				sids[gid] = d.u16(cs + 1 + 2*(gid-1))
+			}
+		case 1, 2:
+			at := cs + 1
+			for gid := 1; gid < nGlyphs; {
+				first, left := d.u16(at), d.u8(at+2)
+				at += 3
+				if d.u8(cs) == 2 {
+					left = d.u16(at - 1)
+					at++
+				}
+				for i := 0; i <= left && gid < nGlyphs; i++ {
+					sids[gid] = first + i
+					gid++
+				}
+			}
+		}
+	}
+	if cidKeyed {
+		p.cids = sids
+		return p
+	}
+	p.names = make([]string, nGlyphs)
+	for gid, s := range sids {
+		p.names[gid] = sid(s)
+	}
+
+	switch enc := arg(16); enc {
+	case 0:
+		for code, name := range standardEncodingNames {
+			if name != "" {
+				p.encoding[code] = name
+			}
+		}
+	case 1: // Expert encoding: not built in
+	default:
+		format := d.u8(enc)
+		at := enc + 1
+		switch format & 0x7F {
+		case 0:
+			n := d.u8(at)
+			for gid := 1; gid <= n && gid < nGlyphs; gid++ {
+				p.encoding[d.u8(at+gid)] = p.names[gid]
+			}
+			at += 1 + n
+		case 1:
+			n := d.u8(at)
+			gid := 1
+			for i := 0; i < n; i++ {
+				first, left := d.u8(at+1+2*i), d.u8(at+2+2*i)
+				for code := first; code <= first+left && gid < nGlyphs; code++ {
+					p.encoding[code] = p.names[gid]
+					gid++
+				}
+			}
+			at += 1 + 2*n
+		}
+		if format&0x80 != 0 { // supplements
+			n := d.u8(at)
+			for i := 0; i < n; i++ {
+				p.encoding[d.u8(at+1+3*i)] = sid(d.u16(at + 2 + 3*i))
+			}
+		}
+	}
+	return p
+}
+
+// parseType1 parses a Type 1 font program, whose first length1 bytes
+// are the clear-text portion that normally defines the built-in encoding.
+// See Adobe Type 1 Font Format, chapter 2.
+func parseType1(data []byte, length1 int) *fontProgram {
+	if length1 <= 0 || length1 > len(data) {
+		length1 = len(data)
+		if i := bytes.Index(data, []byte("eexec")); i >= 0 {
+			length1 = i + len("eexec")
+			for length1 < len(data) && isSpace(data[length1]) {
+				length1++
+			}
+		}
+	}
+	p := &fontProgram{encoding: make(map[int]string)}
+	p.readType1Encoding(data[:length1])
+	if len(p.encoding) == 0 {
+		// Some fonts define the encoding in the encrypted portion.
+		for _, m := range type1Put.FindAllSubmatch(eexecDecrypt(data[length1:]), -1) {
+			var code int
+			fmt.Sscan(string(m[1]), &code)
+			p.encoding[code] = string(m[2])
+		}
+	}
+	return p
+}
+
+var type1Put = regexp.MustCompile(`dup\s+(\d+)\s*/([^\s/\[\]{}()<>%]+)\s+put`)
+
+// readType1Encoding runs the clear-text portion of a Type 1 font through
+// Interpret, recording the names stored into the Encoding array.
+// Procedures, which Interpret cannot execute, are replaced by null.
+func (p *fontProgram) readType1Encoding(clear []byte) {
+	defer func() {
+		recover() // keep whatever was recorded before the failure
+	}()
+	var procs []int // stack depth at each open brace
+	b := newBuffer(ioutil.NopCloser(bytes.NewReader(clear)), 0)
+	interpret(b, func(stk *Stack, op string) {
+		if len(procs) > 0 && op != "{" && op != "}" {
+			return
+		}
+		switch op {
+		case "{":
+			procs = append(procs, stk.Len())
+		case "}":
+			if len(procs) == 0 {
+				return
+			}
+			n := procs[len(procs)-1]
+			procs = procs[:len(procs)-1]
+			for stk.Len() > n {
+				stk.Pop()
+			}
+			if len(procs) == 0 {
+				stk.Push(Value{})
+			}
+		case "array":
+			stk.Push(Value{nil, objptr{}, make(array, stk.Pop().Int64())})
+		case "for":
+			for i := 0; i < 4; i++ {
+				stk.Pop()
+			}
+		case "put":
+			val, key, a := stk.Pop(), stk.Pop(), stk.Pop()
+			if x, ok := a.data.(array); ok && len(x) == 256 && key.Kind() == Integer && val.Kind() == Name {
+				if i := int(key.Int64()); 0 <= i && i < 256 {
+					x[i] = val.data
+					p.encoding[i] = val.Name()
+				}
+			}
+		case "StandardEncoding":
+			for code, name := range standardEncodingNames {
+				if name != "" {
+					p.encoding[code] = name
+				}
+			}
+			stk.Push(Value{nil, objptr{}, name("StandardEncoding")})
+		case "currentfile":
+			stk.Push(Value{})
+		case "eexec":
+			panic("eexec") // the rest of the data is encrypted
+		}
+	})
+}
+
+// eexecDecrypt decrypts the encrypted portion of a Type 1 font,
+// dropping the four random bytes at its start.
+func eexecDecrypt(data []byte) []byte {
+	if len(data) >= 4 && unhex(data[0]) >= 0 && unhex(data[1]) >= 0 && unhex(data[2]) >= 0 && unhex(data[3]) >= 0 {
+		// hexadecimal form
+		var digits []int
+		for _, c := range data {
+			if x := unhex(c); x >= 0 {
+				digits = append(digits, x)
+			}
+		}
+		data = make([]byte, len(digits)/2)
+		for i := range data {
+			data[i] = byte(digits[2*i]<<4 | digits[2*i+1])
+		}
+	}
+	r := uint16(55665)
+	out := make([]byte, len(data))
+	for i, c := range data {
+		out[i] = c ^ byte(r>>8)
+		r = (uint16(c)+r)*52845 + 22719
+	}
+	if len(out) < 4 {
+		return nil
+	}
+	return out[4:]
+}
+
+// macGlyphNames are the standard Macintosh glyph names used by
+// TrueType post tables of format 1.0 and 2.0.
+var macGlyphNames = [258]string{
+	".notdef", ".null", "nonmarkingreturn", "space", "exclam", "quotedbl", "numbersign", "dollar",
+	"percent", "ampersand", "quotesingle", "parenleft", "parenright", "asterisk", "plus", "comma",
+	"hyphen", "period", "slash", "zero", "one", "two", "three", "four",
+	"five", "six", "seven", "eight", "nine", "colon", "semicolon", "less",
+	"equal", "greater", "question", "at", "A", "B", "C", "D",
+	"E", "F", "G", "H", "I", "J", "K", "L",
+	"M", "N", "O", "P", "Q", "R", "S", "T",
+	"U", "V", "W", "X", "Y", "Z", "bracketleft", "backslash",
+	"bracketright", "asciicircum", "underscore", "grave", "a", "b", "c", "d",
+	"e", "f", "g", "h", "i", "j", "k", "l",
+	"m", "n", "o", "p", "q", "r", "s", "t",
+	"u", "v", "w", "x", "y", "z", "braceleft", "bar",
+	"braceright", "asciitilde", "Adieresis", "Aring", "Ccedilla", "Eacute", "Ntilde", "Odieresis",
+	"Udieresis", "aacute", "agrave", "acircumflex", "adieresis", "atilde", "aring", "ccedilla",
+	"eacute", "egrave", "ecircumflex", "edieresis", "iacute", "igrave", "icircumflex", "idieresis",
+	"ntilde", "oacute", "ograve", "ocircumflex", "odieresis", "otilde", "uacute", "ugrave",
+	"ucircumflex", "udieresis", "dagger", "degree", "cent", "sterling", "section", "bullet",
+	"paragraph", "germandbls", "registered", "copyright", "trademark", "acute", "dieresis", "notequal",
+	"AE", "Oslash", "infinity", "plusminus", "lessequal", "greaterequal", "yen", "mu",
+	"partialdiff", "summation", "product", "pi", "integral", "ordfeminine", "ordmasculine", "Omega",
+	"ae", "oslash", "questiondown", "exclamdown", "logicalnot", "radical", "florin", "approxequal",
+	"Delta", "guillemotleft", "guillemotright", "ellipsis", "nonbreakingspace", "Agrave", "Atilde", "Otilde",
+	"OE", "oe", "endash", "emdash", "quotedblleft", "quotedblright", "quoteleft", "quoteright",
+	"divide", "lozenge", "ydieresis", "Ydieresis", "fraction", "currency", "guilsinglleft", "guilsinglright",
+	"fi", "fl", "daggerdbl", "periodcentered", "quotesinglbase", "quotedblbase", "perthousand", "Acircumflex",
+	"Ecircumflex", "Aacute", "Edieresis", "Egrave", "Iacute", "Icircumflex", "Idieresis", "Igrave",
+	"Oacute", "Ocircumflex", "apple", "Ograve", "Uacute", "Ucircumflex", "Ugrave", "dotlessi",
+	"circumflex", "tilde", "macron", "breve", "dotaccent", "ring", "cedilla", "hungarumlaut",
+	"ogonek", "caron", "Lslash", "lslash", "Scaron", "scaron", "Zcaron", "zcaron",
+	"brokenbar", "Eth", "eth", "Yacute", "yacute", "Thorn", "thorn", "minus",
+	"multiply", "onesuperior", "twosuperior", "threesuperior", "onehalf", "onequarter", "threequarters", "franc",
+	"Gbreve", "gbreve", "Idotaccent", "Scedilla", "scedilla", "Cacute", "cacute", "Ccaron",
+	"ccaron", "dcroat",
+}
+
+// cffStandardStrings are the predefined strings of the Compact Font Format,
+// indexed by string identifier (SID). See Adobe Technical Note #5176, Appendix A.
+var cffStandardStrings = [391]string{
+	".notdef", "space", "exclam", "quotedbl", "numbersign", "dollar", "percent", "ampersand",
+	"quoteright", "parenleft", "parenright", "asterisk", "plus", "comma", "hyphen", "period",
+	"slash", "zero", "one", "two", "three", "four", "five", "six",
+	"seven", "eight", "nine", "colon", "semicolon", "less", "equal", "greater",
+	"question", "at", "A", "B", "C", "D", "E", "F",
+	"G", "H", "I", "J", "K", "L", "M", "N",
+	"O", "P", "Q", "R", "S", "T", "U", "V",
+	"W", "X", "Y", "Z", "bracketleft", "backslash", "bracketright", "asciicircum",
+	"underscore", "quoteleft", "a", "b", "c", "d", "e", "f",
+	"g", "h", "i", "j", "k", "l", "m", "n",
+	"o", "p", "q", "r", "s", "t", "u", "v",
+	"w", "x", "y", "z", "braceleft", "bar", "braceright", "asciitilde",
+	"exclamdown", "cent", "sterling", "fraction", "yen", "florin", "section", "currency",
+	"quotesingle", "quotedblleft", "guillemotleft", "guilsinglleft", "guilsinglright", "fi", "fl", "endash",
+	"dagger", "daggerdbl", "periodcentered", "paragraph", "bullet", "quotesinglbase", "quotedblbase", "quotedblright",
+	"guillemotright", "ellipsis", "perthousand", "questiondown", "grave", "acute", "circumflex", "tilde",
+	"macron", "breve", "dotaccent", "dieresis", "ring", "cedilla", "hungarumlaut", "ogonek",
+	"caron", "emdash", "AE", "ordfeminine", "Lslash", "Oslash", "OE", "ordmasculine",
+	"ae", "dotlessi", "lslash", "oslash", "oe", "germandbls", "onesuperior", "logicalnot",
+	"mu", "trademark", "Eth", "onehalf", "plusminus", "Thorn", "onequarter", "divide",
+	"brokenbar", "degree", "thorn", "threequarters", "twosuperior", "registered", "minus", "eth",
+	"multiply", "threesuperior", "copyright", "Aacute", "Acircumflex", "Adieresis", "Agrave", "Aring",
+	"Atilde", "Ccedilla", "Eacute", "Ecircumflex", "Edieresis", "Egrave", "Iacute", "Icircumflex",
+	"Idieresis", "Igrave", "Ntilde", "Oacute", "Ocircumflex", "Odieresis", "Ograve", "Otilde",
+	"Scaron", "Uacute", "Ucircumflex", "Udieresis", "Ugrave", "Yacute", "Ydieresis", "Zcaron",
+	"aacute", "acircumflex", "adieresis", "agrave", "aring", "atilde", "ccedilla", "eacute",
+	"ecircumflex", "edieresis", "egrave", "iacute", "icircumflex", "idieresis", "igrave", "ntilde",
+	"oacute", "ocircumflex", "odieresis", "ograve", "otilde", "scaron", "uacute", "ucircumflex",
+	"udieresis", "ugrave", "yacute", "ydieresis", "zcaron", "exclamsmall", "Hungarumlautsmall", "dollaroldstyle",
+	"dollarsuperior", "ampersandsmall", "Acutesmall", "parenleftsuperior", "parenrightsuperior", "twodotenleader", "onedotenleader", "zerooldstyle",
+	"oneoldstyle", "twooldstyle", "threeoldstyle", "fouroldstyle", "fiveoldstyle", "sixoldstyle", "sevenoldstyle", "eightoldstyle",
+	"nineoldstyle", "commasuperior", "threequartersemdash", "periodsuperior", "questionsmall", "asuperior", "bsuperior", "centsuperior",
+	"dsuperior", "esuperior", "isuperior", "lsuperior", "msuperior", "nsuperior", "osuperior", "rsuperior",
+	"ssuperior", "tsuperior", "ff", "ffi", "ffl", "parenleftinferior", "parenrightinferior", "Circumflexsmall",
+	"hyphensuperior", "Gravesmall", "Asmall", "Bsmall", "Csmall", "Dsmall", "Esmall", "Fsmall",
+	"Gsmall", "Hsmall", "Ismall", "Jsmall", "Ksmall", "Lsmall", "Msmall", "Nsmall",
+	"Osmall", "Psmall", "Qsmall", "Rsmall", "Ssmall", "Tsmall", "Usmall", "Vsmall",
+	"Wsmall", "Xsmall", "Ysmall", "Zsmall", "colonmonetary", "onefitted", "rupiah", "Tildesmall",
+	"exclamdownsmall", "centoldstyle", "Lslashsmall", "Scaronsmall", "Zcaronsmall", "Dieresissmall", "Brevesmall", "Caronsmall",
+	"Dotaccentsmall", "Macronsmall", "figuredash", "hypheninferior", "Ogoneksmall", "Ringsmall", "Cedillasmall", "questiondownsmall",
+	"oneeighth", "threeeighths", "fiveeighths", "seveneighths", "onethird", "twothirds", "zerosuperior", "foursuperior",
+	"fivesuperior", "sixsuperior", "sevensuperior", "eightsuperior", "ninesuperior", "zeroinferior", "oneinferior", "twoinferior",
+	"threeinferior", "fourinferior", "fiveinferior", "sixinferior", "seveninferior", "eightinferior", "nineinferior", "centinferior",
+	"dollarinferior", "periodinferior", "commainferior", "Agravesmall", "Aacutesmall", "Acircumflexsmall", "Atildesmall", "Adieresissmall",
+	"Aringsmall", "AEsmall", "Ccedillasmall", "Egravesmall", "Eacutesmall", "Ecircumflexsmall", "Edieresissmall", "Igravesmall",
+	"Iacutesmall", "Icircumflexsmall", "Idieresissmall", "Ethsmall", "Ntildesmall", "Ogravesmall", "Oacutesmall", "Ocircumflexsmall",
+	"Otildesmall", "Odieresissmall", "OEsmall", "Oslashsmall", "Ugravesmall", "Uacutesmall", "Ucircumflexsmall", "Udieresissmall",
+	"Yacutesmall", "Thornsmall", "Ydieresissmall", "001.000", "001.001", "001.002", "001.003", "Black",
+	"Bold", "Book", "Light", "Medium", "Regular", "Roman", "Semibold",
+}
@@ -0,0 +1,166 @@
+package pdf
+
+import (
+	"encoding/binary"
+	"sort"
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+func TestType1Encoding(t *testing.T) {
+	clear := `%!PS-AdobeFont-1.0: CMR10 003.002
+12 dict begin
+/FontInfo 9 dict dup begin
+/FullName (CMR10) readonly def
+end readonly def
+/FontName /ABCDEF+CMR10 def
+/Encoding 256 array
+0 1 255 {1 index exch /.notdef put} for
+dup 1 /fi put
+dup 65 /A put
+dup 97 /a put
+readonly def
+/FontMatrix [0.001 0 0 0.001 0 0] readonly def
+/FontBBox {-40 -250 1009 750} readonly def
+currentdict end
+currentfile eexec
+`
+	p := parseType1([]byte(clear+"\x01\x02\x03\x04garbage"), len(clear))
+	assert.Equal(t, map[int]string{1: "fi", 65: "A", 97: "a"}, p.encoding)
+}
+
+func TestTrueTypeCmap(t *testing.T) {
+	// A font with a (3,1) format 4 cmap mapping U+0041-U+0043 to glyphs 1-3
+	// and a (1,0) format 0 cmap mapping code 0x61 to glyph 2.
+	be := binary.BigEndian
+	var cmap []byte
+	cmap = append(cmap, 0, 0, 0, 2)
+	cmap = append(cmap, 0, 3, 0, 1, 0, 0, 0, 20)
+	cmap = append(cmap, 0, 1, 0, 0, 0, 0, 0, 52)
+	format4 := make([]byte, 32)
+	for i, x := range []uint16{4, 32, 0, 4, 4, 1, 0, 0x43, 0xFFFF, 0, 0x41, 0xFFFF, 0xFFC0, 1, 0, 0} {
+		be.PutUint16(format4[2*i:], x)
+	}
+	cmap = append(cmap, format4...)
+	format0 := make([]byte, 6+256)
+	format0[6+0x61] = 2
+	cmap = append(cmap, format0...)
+
+	font := make([]byte, 12+16)
+	be.PutUint16(font[4:], 1)
+	copy(font[12:], "cmap")
+	be.PutUint32(font[12+8:], uint32(len(font)))
+	be.PutUint32(font[12+12:], uint32(len(cmap)))
+	font = append(font, cmap...)
+
+	p := parseSFNT(fontData(font))
+	assert.Equal(t, map[int]int{0x41: 1, 0x42: 2, 0x43: 3}, p.cmaps[[2]int{3, 1}])
+	gid, ok := p.trueTypeGlyph(0x61)
+	assert.Equal(t, true, ok)
+	assert.Equal(t, "B", p.glyphText(gid))
+}
+
+// testSFNT returns a TrueType or OpenType font made of the given tables.
+func testSFNT(tables map[string][]byte) []byte {
+	be := binary.BigEndian
+	var tags []string
+	for tag := range tables {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	font := make([]byte, 12+16*len(tags))
+	be.PutUint16(font[4:], uint16(len(tags)))
+	for i, tag := range tags {
+		rec := font[12+16*i:]
+		copy(rec, tag)
+		be.PutUint32(rec[8:], uint32(len(font)))
+		be.PutUint32(rec[12:], uint32(len(tables[tag])))
+		font = append(font, tables[tag]...)
+	}
+	return font
+}
+
+func TestCmapLimit(t *testing.T) {
+	// Two format 12 subtables, each claiming every code point, and a
+	// group whose start follows its end.
+	be := binary.BigEndian
+	cmap := []byte{0, 0, 0, 2, 0, 3, 0, 10, 0, 0, 0, 20, 0, 0, 0, 4, 0, 0, 0, 20}
+	format12 := make([]byte, 16+24)
+	be.PutUint16(format12[0:], 12)
+	be.PutUint32(format12[12:], 0xFFFFFFFF)
+	for i, x := range []uint32{10, 5, 1, 0, 0xFFFFFFFF, 1} {
+		be.PutUint32(format12[16+4*i:], x)
+	}
+	cmap = append(cmap, format12...)
+
+	cmaps := parseCmapTable(fontData(cmap))
+	assert.Equal(t, maxCmapCodes, len(cmaps[[2]int{3, 10}]))
+	assert.Equal(t, 0, len(cmaps[[2]int{0, 4}]))
+	assert.Equal(t, 0x42, cmaps[[2]int{3, 10}][0x41])
+}
+
+func TestCIDKeyedCFF(t *testing.T) {
+	// A CID-keyed CFF font with glyphs 1 and 2 for CIDs 5 and 7,
+	// in an OpenType font whose cmap maps U+0041 and U+0042 to them.
+	be := binary.BigEndian
+	cff := []byte{1, 0, 4, 1}
+	cff = append(cff, 0, 1, 1, 1, 2, 'F') // Name INDEX
+	dict := []byte{
+		28, 1, 0x87, 28, 1, 0x88, 139, 12, 30, // ROS
+		28, 0, 36, 15, // charset
+		28, 0, 41, 17, // CharStrings
+	}
+	cff = append(cff, 0, 1, 1, 1, byte(1+len(dict)))
+	cff = append(cff, dict...)
+	cff = append(cff, 0, 0, 0, 0)          // String and Global Subr INDEXes
+	cff = append(cff, 0, 0, 5, 0, 7)       // charset
+	cff = append(cff, 0, 3, 1, 1, 2, 3, 4) // CharStrings
+	cff = append(cff, 14, 14, 14)
+
+	cmap := []byte{0, 0, 0, 1, 0, 3, 0, 1, 0, 0, 0, 12}
+	format4 := make([]byte, 32)
+	for i, x := range []uint16{4, 32, 0, 4, 4, 1, 0, 0x42, 0xFFFF, 0, 0x41, 0xFFFF, 0xFFC0, 1, 0, 0} {
+		be.PutUint16(format4[2*i:], x)
+	}
+	cmap = append(cmap, format4...)
+	font := testSFNT(map[string][]byte{"CFF ": cff, "cmap": cmap})
+
+	p := parseSFNT(fontData(font))
+	assert.Equal(t, []int{0, 5, 7}, p.cids)
+
+	r := newTestPDF(t,
+		`<< /Type /Catalog /Pages 2 0 R >>`,
+		`<< /Type /Pages /Kids [3 0 R] /Count 1 >>`,
+		`<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 4 0 R
+		   /Resources << /Font << /F1 << /Type /Font /Subtype /Type0 /BaseFont /F /Encoding /Identity-H
+		     /DescendantFonts [<< /Type /Font /Subtype /CIDFontType0 /BaseFont /F
+		       /CIDSystemInfo << /Registry (Adobe) /Ordering (Identity) /Supplement 0 >>
+		       /FontDescriptor << /Type /FontDescriptor /FontName /F /FontFile3 5 0 R >> >>] >> >> >> >>`,
+		"stream\nBT /F1 12 Tf 72 700 Td <00050007> Tj ET",
+		"<< /Subtype /OpenType >>\nstream\n"+string(font),
+	)
+	text, err := r.Page(1).GetPlainText(nil)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "AB\n", text)
+}
+
+func TestFontProgramCache(t *testing.T) {
+	// The streams hold no font, so each parses to nil, but is cached all the same.
+	r := new(Reader)
+	program := func(id uint32) {
+		ptr := objptr{id, 0}
+		r.fontProgram("FontFile2", Value{r, ptr, stream{dict{}, ptr, 0}})
+	}
+	for id := uint32(1); id <= maxFontPrograms; id++ {
+		program(id)
+	}
+	program(1) // used again, so now the most recent
+	program(maxFontPrograms + 1)
+	assert.Equal(t, maxFontPrograms, len(r.fontPrograms))
+	_, ok := r.fontPrograms[objptr{1, 0}]
+	assert.Equal(t, true, ok)
+	_, ok = r.fontPrograms[objptr{2, 0}]
+	assert.Equal(t, false, ok)
+	assert.Equal(t, objptr{maxFontPrograms + 1, 0}, r.fontOrder[len(r.fontOrder)-1])
+}
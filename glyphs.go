@@ -0,0 +1,90 @@
+// Copyright 2014 The Go Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pdf
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// A Glyph is a character code shown on a page, with what its
+// font says about it, for finding out why text decodes as it does.
+type Glyph struct {
+	Text      Text    // the glyph as in Page.Content, without normalization; S is empty if the code has no text
+	Code      string  // the bytes of the character code
+	CID       int     // the character identifier of the code in a Type0 font; the code itself in a simple font
+	Name      string  // the glyph name that the encoding of a simple font gives the code, or ""
+	Width     float64 // the advance width the font gives the glyph, in glyph space units (1/1000 of text space)
+	Font      Font    // the font that shows the glyph
+	NoUnicode bool    // whether the font maps the code to no Unicode text, which is then U+FFFD or empty
+}
+
+// Glyphs returns the glyphs shown on the page, in the order they are shown.
+// Unlike Page.Content, it includes glyphs that decode to no text and
+// does not apply the Reader's text normalization or replace text marked
+// with ActualText. Coordinates are given in display space if set by
+// SetExtractOptions.
+func (p Page) Glyphs() []Glyph {
+	p = p.fixOptions()
+	var out []Glyph
+	p.walkGlyphs(func(g Glyph) {
+		out = append(out, g)
+	})
+	return out
+}
+
+// WalkGlyphs calls fn for each glyph shown on the page, as returned by
+// Glyphs, as it interprets the content stream, without collecting the
+// page's glyphs. It returns an error, and stops calling fn, if the
+// content stream is malformed.
+func (p Page) WalkGlyphs(fn func(Glyph)) (err error) {
+	p = p.fixOptions()
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New(fmt.Sprint(r))
+		}
+	}()
+	p.walkGlyphs(fn)
+	return nil
+}
+
+// walkGlyphs calls fn for each glyph shown on the page.
+func (p Page) walkGlyphs(fn func(Glyph)) {
+	d := p.display()
+	p.walkContent(nil, func(g Glyph) {
+		if d != nil {
+			t := []Text{g.Text}
+			d.flipText(t)
+			g.Text = t[0]
+		}
+		fn(g)
+	})
+}
+
+// glyph returns the details of the character code shown as t, using
+// the CMap cids of a Type0 font. raw is the text that f's encoding
+// gives the code.
+func (f Font) glyph(t Text, cids *cmap, code, raw string) Glyph {
+	g := Glyph{
+		Text:      t,
+		Code:      code,
+		CID:       codeInt(code),
+		Width:     f.codeWidth(cids, code),
+		Font:      f,
+		NoUnicode: raw == "" || strings.ContainsRune(raw, noRune),
+	}
+	if f.Subtype() == "Type0" {
+		if cids != nil {
+			g.CID = cids.cid(code)
+		}
+	} else if len(code) == 1 {
+		g.Name = f.simpleEncoding().names[code[0]]
+	}
+	if g.NoUnicode {
+		g.Text.S = strings.Replace(g.Text.S, string(noRune), "", -1)
+	}
+	return g
+}
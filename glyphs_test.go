@@ -0,0 +1,40 @@
+package pdf
+
+import (
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+func TestGlyphs(t *testing.T) {
+	r := newTestPDF(t,
+		`<< /Type /Catalog /Pages 2 0 R >>`,
+		`<< /Type /Pages /Kids [3 0 R] /Count 1 >>`,
+		`<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 4 0 R
+		   /Resources << /Font << /F1 << /Type /Font /Subtype /Type1 /BaseFont /ABCDEF+Helvetica
+		     /Encoding << /Differences [66 /g123] >> >> >> >> >>`,
+		`stream
+BT /F1 10 Tf 1 0 0 1 72 700 Tm (AB) Tj ET`,
+	)
+	gs := r.Page(1).Glyphs()
+	assert.Equal(t, 2, len(gs))
+
+	a := gs[0]
+	assert.Equal(t, "A", a.Text.S)
+	assert.Equal(t, "Helvetica", a.Text.Font)
+	assert.Equal(t, 72.0, a.Text.X)
+	assert.Equal(t, "A", a.Code)
+	assert.Equal(t, 65, a.CID)
+	assert.Equal(t, "A", a.Name)
+	assert.Equal(t, 667.0, a.Width)
+	assert.Equal(t, "ABCDEF+Helvetica", a.Font.BaseFont())
+	assert.Equal(t, false, a.NoUnicode)
+
+	b := gs[1]
+	assert.Equal(t, "", b.Text.S)
+	assert.Equal(t, "B", b.Code)
+	assert.Equal(t, "g123", b.Name)
+	assert.Equal(t, true, b.NoUnicode)
+	assert.Equal(t, 72+6.67, b.Text.X)
+
+}
@@ -1,9 +1,16 @@
 module github.com/shouldend/pdf
 
-go 1.12
+go 1.17
 
 require (
 	github.com/bmizerany/assert v0.0.0-20160611221934-b7ed37b82869
-	github.com/kr/pretty v0.2.0 // indirect
 	github.com/llgcode/draw2d v0.0.0-20200110163050-b96d8208fcfc
+	golang.org/x/text v0.3.8
+)
+
+require (
+	github.com/golang/freetype v0.0.0-20170609003504-e2365dfdc4a0 // indirect
+	github.com/kr/pretty v0.2.0 // indirect
+	github.com/kr/text v0.1.0 // indirect
+	golang.org/x/image v0.0.0-20180708004352-c73c2afc3b81 // indirect
 )
@@ -0,0 +1,257 @@
+// Copyright 2014 The Go Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Inline images. See PDF 32000-1:2008, §8.9.7.
+
+package pdf
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+// inlineKeys maps the abbreviated keys of inline image dictionaries to their full names.
+var inlineKeys = map[name]name{
+	"BPC": "BitsPerComponent",
+	"CS":  "ColorSpace",
+	"D":   "Decode",
+	"DP":  "DecodeParms",
+	"F":   "Filter",
+	"H":   "Height",
+	"IM":  "ImageMask",
+	"I":   "Interpolate",
+	"L":   "Length",
+	"W":   "Width",
+}
+
+// inlineNames maps the abbreviated colour space and filter names of inline images to their full names.
+var inlineNames = map[name]name{
+	"G":    "DeviceGray",
+	"RGB":  "DeviceRGB",
+	"CMYK": "DeviceCMYK",
+	"I":    "Indexed",
+	"AHx":  "ASCIIHexDecode",
+	"A85":  "ASCII85Decode",
+	"LZW":  "LZWDecode",
+	"Fl":   "FlateDecode",
+	"RL":   "RunLengthDecode",
+	"CCF":  "CCITTFaxDecode",
+	"DCT":  "DCTDecode",
+}
+
+// An imagePlacement is an image painted by a content stream:
+// an image XObject, or an inline image.
+type imagePlacement struct {
+	xobj objptr // the image XObject, if hdr is nil
+	hdr  dict   // the dictionary of an inline image
+	data []byte
+	res  Value // the resources in which the image's colour space is named
+	rect Rect  // the image of the unit square under the CTM
+}
+
+// unitSquare is the square that images are painted
+// into, mapped to the page by the CTM.
+var unitSquare = Rect{Point{0, 0}, Point{1, 1}}
+
+// readInlineImage reads an inline image following the BI operator:
+// its dictionary, up to the ID operator, and its data, up to EI.
+// Abbreviated keys and names in the dictionary are expanded.
+func (b *buffer) readInlineImage() (dict, []byte) {
+	hdr := make(dict)
+	for {
+		tok := b.readToken()
+		if tok == keyword("ID") || tok == io.EOF {
+			break
+		}
+		key, ok := tok.(name)
+		if !ok {
+			b.errorf("unexpected %v in inline image dictionary", tok)
+		}
+		if full, ok := inlineKeys[key]; ok {
+			key = full
+		}
+		hdr[key] = expandInline(b.readObject())
+	}
+
+	// A single white-space character separates ID from the data.
+	if c := b.readByte(); !isSpace(c) {
+		b.unreadByte()
+	}
+	var data []byte
+	if n := inlineLength(hdr); n >= 0 {
+		data = b.readBytes(n)
+	}
+	return hdr, append(data, b.readToEI()...)
+}
+
+// expandInline expands the abbreviated names in x, a value in an inline image dictionary.
+func expandInline(x object) object {
+	switch x := x.(type) {
+	case name:
+		if full, ok := inlineNames[x]; ok {
+			return full
+		}
+	case array:
+		for i := range x {
+			x[i] = expandInline(x[i])
+		}
+	}
+	return x
+}
+
+// inlineLength returns the length of the data of the inline image
+// with dictionary hdr, from its Length or, if the data is not
+// filtered, its size and colour space, or -1 if it is not known.
+func inlineLength(hdr dict) int {
+	if n, ok := hdr["Length"].(int64); ok && n >= 0 {
+		return int(n)
+	}
+	if hdr["Filter"] != nil {
+		return -1
+	}
+	w, _ := hdr["Width"].(int64)
+	h, _ := hdr["Height"].(int64)
+	bpc, _ := hdr["BitsPerComponent"].(int64)
+	comps := int64(-1)
+	if mask, _ := hdr["ImageMask"].(bool); mask {
+		comps, bpc = 1, 1
+	} else {
+		cs := hdr["ColorSpace"]
+		if a, ok := cs.(array); ok && len(a) > 0 {
+			cs = a[0]
+		}
+		switch cs {
+		case name("DeviceGray"), name("Indexed"), name("CalGray"):
+			comps = 1
+		case name("DeviceRGB"), name("CalRGB"), name("Lab"):
+			comps = 3
+		case name("DeviceCMYK"):
+			comps = 4
+		}
+	}
+	if w <= 0 || h <= 0 || bpc <= 0 || comps < 0 || float64(w)*float64(h)*float64(comps*bpc) > maxInlineBits {
+		return -1
+	}
+	return int((w*comps*bpc + 7) / 8 * h)
+}
+
+// maxInlineBits bounds the size of the data of an unfiltered inline
+// image, in bits, so that a header claiming a huge image cannot
+// overflow inlineLength. Larger images are read up to EI instead.
+const maxInlineBits = 1 << 40
+
+// readBytes reads the next n bytes, or as many as remain. The result
+// grows as the bytes are read, since n comes from the content stream
+// and may be far more than remains.
+func (b *buffer) readBytes(n int) []byte {
+	var data []byte
+	for len(data) < n {
+		if b.pos >= len(b.buf) && !b.reload() {
+			break
+		}
+		k := len(b.buf) - b.pos
+		if k > n-len(data) {
+			k = n - len(data)
+		}
+		data = append(data, b.buf[b.pos:b.pos+k]...)
+		b.pos += k
+	}
+	return data
+}
+
+// readToEI reads inline image data of unknown length, returning the
+// bytes up to the EI operator that ends it. To tell EI from the same
+// bytes in binary data, EI must be set off by white space and followed
+// by text, as the content stream continues after the image.
+func (b *buffer) readToEI() []byte {
+	var data []byte
+	for {
+		if b.pos >= len(b.buf) && !b.reload() {
+			return data
+		}
+		c := b.buf[b.pos]
+		b.pos++
+		data = append(data, c)
+		n := len(data)
+		if n < 3 || data[n-2] != 'E' || data[n-1] != 'I' || !isSpace(data[n-3]) {
+			continue
+		}
+		if b.pos >= len(b.buf) {
+			b.reload()
+		}
+		if b.pos >= len(b.buf) || isSpace(b.buf[b.pos]) && isText(b.buf[b.pos:]) {
+			return data[:n-3]
+		}
+	}
+}
+
+// isText reports whether the first bytes of b, which follow
+// a possible EI operator, could be content stream operators.
+func isText(b []byte) bool {
+	if len(b) > 10 {
+		b = b[:10]
+	}
+	for _, c := range b {
+		if c >= 0x7f || c < ' ' && !isSpace(c) {
+			return false
+		}
+	}
+	return true
+}
+
+// inlineImage returns the image with dictionary hdr and data, as read
+// by readInlineImage, decoding the data with its filters.
+// Colour spaces named in the resources res are looked up there.
+func inlineImage(hdr dict, data []byte, res Value) (img Image, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New(fmt.Sprint(r))
+		}
+	}()
+	v := Value{res.r, objptr{}, hdr}
+	var rd io.Reader = bytes.NewReader(data)
+	switch filter, param := v.Key("Filter"), v.Key("DecodeParms"); filter.Kind() {
+	case Name:
+		rd = applyFilter(rd, filter.Name(), param, hdr)
+	case Array:
+		for i := 0; i < filter.Len(); i++ {
+			rd = applyFilter(rd, filter.Index(i).Name(), param.Index(i), hdr)
+		}
+	}
+	content, err := ioutil.ReadAll(rd)
+	if err != nil {
+		return Image{}, err
+	}
+	img = Image{
+		Width:            int(v.Key("Width").Int64()),
+		Height:           int(v.Key("Height").Int64()),
+		BitsPerComponent: int(v.Key("BitsPerComponent").Int64()),
+		Content:          content,
+		SoftMask:         []byte{},
+		Inline:           true,
+	}
+	if v.Key("ImageMask").Bool() {
+		img.BitsPerComponent = 1
+	}
+	cs := v.Key("ColorSpace")
+	if cs.Kind() == Name {
+		cs = colorSpace(cs, res)
+	}
+	switch colorFamily(cs) {
+	case "Indexed":
+		img.ColorSpace = colorFamily(cs.Index(1))
+		switch lookup := cs.Index(3); lookup.Kind() {
+		case String:
+			img.Indexed = []byte(lookup.RawString())
+		case Stream:
+			img.Indexed, _ = ioutil.ReadAll(lookup.Reader())
+		}
+	default:
+		img.ColorSpace = colorFamily(cs)
+	}
+	return img, nil
+}
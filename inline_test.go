@@ -0,0 +1,94 @@
+package pdf
+
+import (
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+func TestInlineImages(t *testing.T) {
+	r := newTestPDF(t,
+		`<< /Type /Catalog /Pages 2 0 R >>`,
+		`<< /Type /Pages /Kids [3 0 R] /Count 1 >>`,
+		`<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 4 0 R
+		   /Resources << /Font << /F1 << /Type /Font /Subtype /Type1 /BaseFont /Helvetica >> >>
+		                 /ColorSpace << /CS0 /DeviceGray >>
+		                 /XObject << /Image1 5 0 R >> >> >>`,
+		"stream\n"+
+			"BT /F1 12 Tf 1 0 0 1 72 700 Tm (Before) Tj ET\n"+
+			"q 20 0 0 10 100 200 cm BI /W 2 /H 2 /BPC 8 /CS /G ID \x00 EI\nEI Q\n"+
+			"q 30 0 0 30 300 300 cm BI /W 2 /H 1 /BPC 8 /CS /RGB /F /AHx ID\n00ff00 ff0000> EI Q\n"+
+			"q 4 0 0 1 0 0 cm BI /W 4 /H 1 /BPC 8 /CS /CS0 ID  EI\x80\nEI Q\n"+
+			"q 50 0 0 50 400 400 cm /Image1 Do Q\n"+
+			"BT /F1 12 Tf 1 0 0 1 72 600 Tm (After) Tj ET",
+		`<< /Type /XObject /Subtype /Image /Width 1 /Height 1 /BitsPerComponent 8 /ColorSpace /DeviceGray >>
+stream
+x`,
+	)
+	p := r.Page(1)
+	text, err := p.GetPlainText(nil)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "Before\nAfter\n", text)
+
+	images := p.Images()
+	assert.Equal(t, 4, len(images))
+	assert.Equal(t, true, images[0].Inline)
+	assert.Equal(t, "DeviceGray", images[0].ColorSpace)
+	assert.Equal(t, []byte("\x00 EI"), images[0].Content)
+	assert.Equal(t, Rect{Point{100, 200}, Point{120, 210}}, images[0].Rect)
+
+	assert.Equal(t, "DeviceRGB", images[1].ColorSpace)
+	assert.Equal(t, 2, images[1].Width)
+	assert.Equal(t, []byte{0x00, 0xff, 0x00, 0xff, 0x00, 0x00}, images[1].Content)
+
+	assert.Equal(t, "DeviceGray", images[2].ColorSpace)
+	assert.Equal(t, []byte(" EI\x80"), images[2].Content)
+	assert.Equal(t, Rect{Point{0, 0}, Point{4, 1}}, images[2].Rect)
+
+	assert.Equal(t, false, images[3].Inline)
+	assert.Equal(t, []byte("x"), images[3].Content)
+	assert.Equal(t, Rect{Point{400, 400}, Point{450, 450}}, images[3].Rect)
+}
+
+func TestImagePlacements(t *testing.T) {
+	r := newTestPDF(t,
+		`<< /Type /Catalog /Pages 2 0 R >>`,
+		`<< /Type /Pages /Kids [3 0 R 8 0 R] /Count 2 /MediaBox [0 0 612 792]
+		   /Resources << /XObject << /Image1 5 0 R /Image2 6 0 R /Fm1 7 0 R >> >> >>`,
+		`<< /Type /Page /Parent 2 0 R /Contents 4 0 R >>`,
+		"stream\n"+
+			"q 10 0 0 10 300 300 cm /Image2 Do Q\n"+
+			"q 1 0 0 1 100 100 cm /Fm1 Do Q\n"+
+			"q 1 0 0 1 0 0 cm BI /W 100000000 /H 100000000 /BPC 8 /CS /G ID \x00\x00 EI Q",
+		`<< /Type /XObject /Subtype /Image /Width 1 /Height 1 /BitsPerComponent 8 /ColorSpace /DeviceGray >>
+stream
+a`,
+		`<< /Type /XObject /Subtype /Image /Width 1 /Height 1 /BitsPerComponent 8 /ColorSpace /DeviceGray >>
+stream
+b`,
+		`<< /Type /XObject /Subtype /Form /BBox [0 0 100 100] /Resources << /XObject << /Im 5 0 R >> >> >>
+stream
+20 0 0 20 5 5 cm /Im Do`,
+		`<< /Type /Page /Parent 2 0 R /Contents 9 0 R >>`,
+		"stream\nq 10 0 0 10 300 300 cm /Image2 Do Q 0 0 re", // malformed
+	)
+
+	// Images are listed as painted, those in forms too, and the huge
+	// inline image is read up to EI rather than allocated.
+	images := r.Page(1).Images()
+	assert.Equal(t, 3, len(images))
+	assert.Equal(t, []byte("b"), images[0].Content)
+	assert.Equal(t, Rect{Point{300, 300}, Point{310, 310}}, images[0].Rect)
+	assert.Equal(t, []byte("a"), images[1].Content)
+	assert.Equal(t, Rect{Point{105, 105}, Point{125, 125}}, images[1].Rect)
+	assert.Equal(t, true, images[2].Inline)
+	assert.Equal(t, 100000000, images[2].Width)
+
+	// A malformed content stream places no images, but the
+	// images in the resources are still listed, by name.
+	images = r.Page(2).Images()
+	assert.Equal(t, 2, len(images))
+	assert.Equal(t, []byte("a"), images[0].Content)
+	assert.Equal(t, Rect{}, images[0].Rect)
+	assert.Equal(t, []byte("b"), images[1].Content)
+}
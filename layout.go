@@ -0,0 +1,270 @@
+// Copyright 2014 The Go Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pdf
+
+import (
+	"math"
+	"sort"
+
+	"golang.org/x/text/unicode/bidi"
+)
+
+// A Layout is the text of a page arranged into blocks in reading order.
+type Layout struct {
+	Blocks []Block
+	Rect   Rect // the bounding box of all the blocks
+}
+
+// A Block is a group of lines set together, such as a paragraph or a heading.
+// Its lines are ordered top to bottom.
+type Block struct {
+	Lines []Line
+	Rect  Rect
+}
+
+// A Line is a group of words sharing a baseline, ordered left to right,
+// or in reading order if the line holds right-to-left text. The words of
+// a rotated or vertical line are ordered along its baseline.
+type Line struct {
+	Words []Word
+	Rect  Rect
+}
+
+// Thresholds for layout analysis, as fractions of the font size.
+const (
+	lineGap   = 1.0 // words further apart than this on a baseline are in different lines
+	columnGap = 1.0 // vertical whitespace at least this wide separates columns
+	blockGap  = 0.5 // horizontal whitespace wider than this separates blocks
+	sizeRatio = 1.1 // lines whose font sizes differ by more than this are in different blocks
+)
+
+// Layout analyzes the arrangement of the text on the page.
+//
+// Words are merged into lines along their baselines, and lines are then
+// divided recursively by the widest gaps in the whitespace between them
+// (the XY-cut algorithm). Vertical gaps, which separate columns, are cut
+// first, so that each column is read top to bottom before the next column
+// to its right; horizontal gaps separate headings, paragraphs and regions
+// that span several columns. What remains after cutting is divided into
+// blocks where the font size changes.
+func (p Page) Layout() Layout {
+	p = p.fixOptions()
+	l := layout(p.pageWords())
+	p.display().flipLayout(&l)
+	return l
+}
+
+func layout(words []Word) Layout {
+	var l Layout
+	l.Blocks = cutLines(buildLines(words))
+	for i, b := range l.Blocks {
+		if i == 0 {
+			l.Rect = b.Rect
+		} else {
+			l.Rect = l.Rect.union(b.Rect)
+		}
+	}
+	return l
+}
+
+// buildLines merges words into lines. A word joins the line whose baseline
+// it shares and whose right end is nearest the word, if that is close enough.
+// Rotated words are compared along their baseline, as if upright.
+func buildLines(words []Word) []Line {
+	words = append([]Word(nil), words...)
+	sort.SliceStable(words, func(i, j int) bool {
+		a, b := words[i], words[j]
+		if !sameDirection(a.Angle, b.Angle) {
+			return a.Angle < b.Angle
+		}
+		if ya, yb := baseline(a.X, a.Y, a.Angle), baseline(b.X, b.Y, a.Angle); ya != yb {
+			return ya > yb
+		}
+		return a.start() < b.start()
+	})
+
+	var (
+		lines []Line
+		ends  []float64 // where each line ends along its baseline
+	)
+	for _, w := range words {
+		size := math.Abs(w.FontSize)
+		best := -1
+		bestGap := 0.0
+		for i := range lines {
+			l := &lines[i]
+			if !sameBaseline(l.Words[len(l.Words)-1], w) {
+				continue
+			}
+			gap := w.start() - ends[i]
+			if gap < -wordShift*size || gap > lineGap*size {
+				continue
+			}
+			if best < 0 || math.Abs(gap) < bestGap {
+				best, bestGap = i, math.Abs(gap)
+			}
+		}
+		if best < 0 {
+			lines = append(lines, Line{Words: []Word{w}, Rect: w.Rect})
+			ends = append(ends, w.end())
+			continue
+		}
+		l := &lines[best]
+		l.Words = append(l.Words, w)
+		l.Rect = l.Rect.union(w.Rect)
+		ends[best] = math.Max(ends[best], w.end())
+	}
+	for i, l := range lines {
+		sort.SliceStable(l.Words, func(i, j int) bool {
+			return l.Words[i].start() < l.Words[j].start()
+		})
+		lines[i].Words = readingOrder(l.Words)
+	}
+	return lines
+}
+
+// start and end return where w starts and ends along its baseline,
+// measured once the baseline is turned upright. For unrotated words
+// these are the left and right edges of the word's box.
+func (w Word) start() float64 {
+	if w.Angle == 0 {
+		return w.Rect.Min.X
+	}
+	x := rotate(Point{w.X, w.Y}, w.Angle).X
+	return math.Min(x, x+w.W)
+}
+
+func (w Word) end() float64 {
+	if w.Angle == 0 {
+		return w.Rect.Max.X
+	}
+	x := rotate(Point{w.X, w.Y}, w.Angle).X
+	return math.Max(x, x+w.W)
+}
+
+// readingOrder puts words sorted left to right into reading order.
+// Only lines holding right-to-left text change.
+func readingOrder(words []Word) []Word {
+	rtl := false
+	for _, w := range words {
+		rtl = rtl || isRTL(w.S)
+	}
+	if !rtl {
+		return words
+	}
+	classes := make([]bidi.Class, 0, 2*len(words))
+	for i, w := range words {
+		if i > 0 {
+			classes = append(classes, bidi.WS)
+		}
+		classes = append(classes, bidiClass(w.S))
+	}
+	out := make([]Word, 0, len(words))
+	for _, i := range visualToLogical(classes) {
+		if i%2 == 0 {
+			out = append(out, words[i/2])
+		}
+	}
+	return out
+}
+
+// cutLines divides lines into blocks in reading order.
+func cutLines(lines []Line) []Block {
+	if len(lines) == 0 {
+		return nil
+	}
+	size := medianSize(lines)
+
+	// Columns: a vertical strip of whitespace crossing every line.
+	if left, right, ok := cutAt(lines, columnGap*size, func(r Rect) (float64, float64) {
+		return r.Min.X, r.Max.X
+	}); ok {
+		return append(cutLines(left), cutLines(right)...)
+	}
+
+	// Rows: a horizontal strip of whitespace, cut top first.
+	if bottom, top, ok := cutAt(lines, blockGap*size, func(r Rect) (float64, float64) {
+		return r.Min.Y, r.Max.Y
+	}); ok {
+		return append(cutLines(top), cutLines(bottom)...)
+	}
+
+	sort.SliceStable(lines, func(i, j int) bool {
+		return lines[i].Rect.Max.Y > lines[j].Rect.Max.Y
+	})
+	var blocks []Block
+	for i, l := range lines {
+		if i > 0 {
+			b := &blocks[len(blocks)-1]
+			prev := lineSize(b.Lines[len(b.Lines)-1])
+			if cur := lineSize(l); math.Max(prev, cur) <= sizeRatio*math.Min(prev, cur) {
+				b.Lines = append(b.Lines, l)
+				b.Rect = b.Rect.union(l.Rect)
+				continue
+			}
+		}
+		blocks = append(blocks, Block{Lines: []Line{l}, Rect: l.Rect})
+	}
+	return blocks
+}
+
+// cutAt looks for the widest gap at least min wide between the intervals
+// that span returns for the lines' rectangles, and splits the lines into
+// those before the gap and those after it.
+func cutAt(lines []Line, min float64, span func(Rect) (float64, float64)) (before, after []Line, ok bool) {
+	type interval struct{ lo, hi float64 }
+	iv := make([]interval, len(lines))
+	for i, l := range lines {
+		iv[i].lo, iv[i].hi = span(l.Rect)
+	}
+	sort.Slice(iv, func(i, j int) bool { return iv[i].lo < iv[j].lo })
+
+	best, at := 0.0, 0.0
+	hi := iv[0].hi
+	for _, x := range iv[1:] {
+		if gap := x.lo - hi; gap >= min && gap > best {
+			best, at = gap, hi
+		}
+		hi = math.Max(hi, x.hi)
+	}
+	if best == 0 {
+		return nil, nil, false
+	}
+	for _, l := range lines {
+		if lo, _ := span(l.Rect); lo > at {
+			after = append(after, l)
+		} else {
+			before = append(before, l)
+		}
+	}
+	return before, after, true
+}
+
+// lineSize returns the largest font size in l.
+func lineSize(l Line) float64 {
+	size := 0.0
+	for _, w := range l.Words {
+		size = math.Max(size, math.Abs(w.FontSize))
+	}
+	return size
+}
+
+// medianSize returns the median of the lines' font sizes.
+func medianSize(lines []Line) float64 {
+	sizes := make([]float64, len(lines))
+	for i, l := range lines {
+		sizes[i] = lineSize(l)
+	}
+	sort.Float64s(sizes)
+	return sizes[len(sizes)/2]
+}
+
+// union returns the smallest rectangle containing both r and s.
+func (r Rect) union(s Rect) Rect {
+	return Rect{
+		Point{math.Min(r.Min.X, s.Min.X), math.Min(r.Min.Y, s.Min.Y)},
+		Point{math.Max(r.Max.X, s.Max.X), math.Max(r.Max.Y, s.Max.Y)},
+	}
+}
@@ -0,0 +1,42 @@
+package pdf
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+func TestLayout(t *testing.T) {
+	var words []Word
+	add := func(s string, x, y, size float64) {
+		for _, f := range strings.Fields(s) {
+			w := Word{FontSize: size, X: x, Y: y, W: float64(len(f)) * size / 2, S: f}
+			w.Rect = Rect{Point{x, y - 0.2*size}, Point{x + w.W, y + 0.8*size}}
+			words = append(words, w)
+			x += w.W + size/4
+		}
+	}
+	// A title over two columns, shown right column first,
+	// with a paragraph break in the left column.
+	add("Two Columns", 100, 750, 20)
+	add("right one", 300, 700, 10)
+	add("right two", 300, 688, 10)
+	add("left one", 100, 700, 10)
+	add("left two", 100, 688, 10)
+	add("left three", 100, 670, 10)
+
+	var got []string
+	for _, b := range layout(words).Blocks {
+		var lines []string
+		for _, l := range b.Lines {
+			var ws []string
+			for _, w := range l.Words {
+				ws = append(ws, w.S)
+			}
+			lines = append(lines, strings.Join(ws, " "))
+		}
+		got = append(got, strings.Join(lines, "/"))
+	}
+	assert.Equal(t, []string{"Two Columns", "left one/left two", "left three", "right one/right two"}, got)
+}
@@ -0,0 +1,158 @@
+// Copyright 2014 The Go Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pdf
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// GetLayoutText returns the text of all pages laid out as by
+// Page.GetLayoutText, each page followed by a form feed.
+// The text of the whole document is held in memory.
+func (r *Reader) GetLayoutText() (io.Reader, error) {
+	var buf bytes.Buffer
+	err := r.WalkPages(func(i int, p Page) error {
+		text, err := p.GetLayoutText()
+		if err != nil {
+			return err
+		}
+		buf.WriteString(text)
+		buf.WriteString("\f")
+		return nil
+	})
+	if err != nil {
+		return &bytes.Buffer{}, err
+	}
+	return &buf, nil
+}
+
+// GetLayoutText returns the page's text laid out as it appears on the page,
+// like the layout mode of pdftotext: each line of text on the page is a line
+// of the result, words are placed on a grid of characters as wide as the
+// page's typical character, so that aligned columns stay aligned, and
+// runs of blank lines stand for vertical space.
+func (p Page) GetLayoutText() (result string, err error) {
+	p = p.fixOptions()
+	defer func() {
+		if r := recover(); r != nil {
+			result = ""
+			err = errors.New(fmt.Sprint(r))
+		}
+	}()
+
+	// Joining hyphenated words would move text between lines.
+	opts := p.extractOptions()
+	opts.Dehyphenate = false
+	return layoutText(opts.finishWords(words(p.content(nil).Text)), p.uprightBox()), nil
+}
+
+// maxLayoutColumns and maxLayoutBlankLines bound the grid of characters
+// that layoutText places words on, however small the typical character.
+const (
+	maxLayoutColumns    = 1000
+	maxLayoutBlankLines = 100
+)
+
+// layoutText lays out words, in upright space, on a grid of characters.
+// Words that begin outside box, the page's crop box, are left out.
+func layoutText(ws []Word, box Rect) string {
+	in := ws[:0:0]
+	for _, w := range ws {
+		if contains(box, Point{w.X, w.Y}) {
+			in = append(in, w)
+		}
+	}
+	ws = in
+	if len(ws) == 0 {
+		return ""
+	}
+
+	// Measure the typical character width and font size,
+	// and the left edge of the text.
+	var widths, sizes []float64
+	left := math.Inf(1)
+	for _, w := range ws {
+		if n := utf8.RuneCountInString(w.S); n > 0 && w.W != 0 {
+			widths = append(widths, math.Abs(w.W)/float64(n))
+		}
+		sizes = append(sizes, math.Abs(w.FontSize))
+		left = math.Min(left, w.X)
+	}
+	size := median(sizes)
+	charWidth := median(widths)
+	if charWidth <= 0 {
+		charWidth = size / 2
+	}
+	if charWidth <= 0 {
+		charWidth = 1
+	}
+	// Words are placed no further right than a few widths of the page.
+	cols := int(math.Min(4*(box.Max.X-box.Min.X)/charWidth, maxLayoutColumns))
+
+	// Group the words into lines by baseline, from the top of the page down.
+	sort.SliceStable(ws, func(i, j int) bool { return ws[i].Y > ws[j].Y })
+	var lines [][]Word
+	for _, w := range ws {
+		if n := len(lines); n > 0 {
+			first := lines[n-1][0]
+			if math.Abs(first.Y-w.Y) <= wordShift*math.Max(math.Abs(first.FontSize), math.Abs(w.FontSize)) {
+				lines[n-1] = append(lines[n-1], w)
+				continue
+			}
+		}
+		lines = append(lines, []Word{w})
+	}
+
+	// The typical distance between lines sets the height of a blank line.
+	var gaps []float64
+	for i := 1; i < len(lines); i++ {
+		if gap := lines[i-1][0].Y - lines[i][0].Y; gap < 2*size {
+			gaps = append(gaps, gap)
+		}
+	}
+	lineHeight := median(gaps)
+	if lineHeight <= 0 {
+		lineHeight = 1.2 * size
+	}
+
+	var b strings.Builder
+	for i, line := range lines {
+		if i > 0 {
+			gap := lines[i-1][0].Y - line[0].Y
+			for n := int(math.Min(math.Floor(gap/lineHeight+0.5), maxLayoutBlankLines+1)); n > 1; n-- {
+				b.WriteString("\n")
+			}
+		}
+		sort.SliceStable(line, func(i, j int) bool { return line[i].X < line[j].X })
+		col := 0
+		for j, w := range line {
+			at := int(math.Min(math.Floor((w.X-left)/charWidth+0.5), float64(cols)))
+			if j > 0 && at <= col {
+				at = col + 1 // keep words that crowd together apart
+			}
+			b.WriteString(strings.Repeat(" ", at-col))
+			b.WriteString(w.S)
+			col = at + utf8.RuneCountInString(w.S)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// median returns the median of xs, or 0 if xs is empty. It sorts xs.
+func median(xs []float64) float64 {
+	if len(xs) == 0 {
+		return 0
+	}
+	sort.Float64s(xs)
+	return xs[len(xs)/2]
+}
@@ -0,0 +1,69 @@
+package pdf
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+func TestLayoutText(t *testing.T) {
+	r := newTestPDF(t,
+		`<< /Type /Catalog /Pages 2 0 R >>`,
+		`<< /Type /Pages /Kids [3 0 R 5 0 R] /Count 2 >>`,
+		`<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 4 0 R
+		   /Resources << /Font << /F1 << /Type /Font /Subtype /Type1 /BaseFont /Helvetica >> >> >> >>`,
+		`stream
+BT /F1 10 Tf
+1 0 0 1 72 700 Tm (Item) Tj 1 0 0 1 300 700 Tm (Qty) Tj 1 0 0 1 400 700 Tm (Price) Tj
+1 0 0 1 72 688 Tm (Widget) Tj 1 0 0 1 300 688 Tm (2) Tj 1 0 0 1 400 688 Tm (9.99) Tj
+1 0 0 1 400 652 Tm (19.98) Tj 1 0 0 1 72 652 Tm (Total) Tj
+ET`,
+		`<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 6 0 R
+		   /Resources << /Font << /F1 << /Type /Font /Subtype /Type1 /BaseFont /Helvetica >> >> >> >>`,
+		`stream
+BT /F1 10 Tf 1 0 0 1 72 700 Tm (Thanks) Tj ET`,
+	)
+	text, err := r.Page(1).GetLayoutText()
+	assert.Equal(t, nil, err)
+	lines := strings.Split(text, "\n")
+	assert.Equal(t, 6, len(lines), text)
+	assert.Equal(t, "", lines[2])
+	assert.Equal(t, "", lines[3])
+	assert.Equal(t, "", lines[5])
+	assert.T(t, strings.HasPrefix(lines[0], "Item "), text)
+	assert.T(t, strings.HasPrefix(lines[4], "Total "), text)
+	qty := strings.Index(lines[0], "Qty")
+	assert.T(t, qty > len("Item "), text)
+	assert.Equal(t, qty, strings.Index(lines[1], "2"), text)
+	price := strings.Index(lines[0], "Price")
+	assert.Equal(t, price, strings.Index(lines[1], "9.99"), text)
+	assert.Equal(t, price, strings.Index(lines[4], "19.98"), text)
+
+	rd, err := r.GetLayoutText()
+	assert.Equal(t, nil, err)
+	all, _ := ioutil.ReadAll(rd)
+	assert.Equal(t, text+"\fThanks\n\f", string(all))
+}
+
+func TestLayoutTextBounds(t *testing.T) {
+	r := newTestPDF(t,
+		`<< /Type /Catalog /Pages 2 0 R >>`,
+		`<< /Type /Pages /Kids [3 0 R] /Count 1 >>`,
+		`<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 4 0 R
+		   /Resources << /Font << /F1 << /Type /Font /Subtype /Type1 /BaseFont /Helvetica >> >> >> >>`,
+		`stream
+BT /F1 0.0001 Tf
+1 0 0 1 1 780 Tm (a) Tj 1 0 0 1 1 779.9999 Tm (b) Tj 1 0 0 1 1 779.9998 Tm (c) Tj
+1 0 0 1 600 10 Tm (d) Tj
+/F1 10 Tf 1 0 0 1 1000000000000 700 Tm (Far) Tj
+ET`,
+	)
+	// The tiny glyphs would place d millions of columns and lines away.
+	text, err := r.Page(1).GetLayoutText()
+	assert.Equal(t, nil, err)
+	assert.T(t, len(text) < 10000, len(text))
+	assert.T(t, !strings.Contains(text, "Far"), text)
+	assert.T(t, strings.Contains(text, "d"), text)
+}
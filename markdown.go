@@ -0,0 +1,463 @@
+// Copyright 2014 The Go Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Conversion of the text of a document to Markdown.
+
+package pdf
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Thresholds for Markdown conversion.
+const (
+	headingRatio    = 1.15 // blocks set at least this much larger than the body text are headings
+	maxHeadingLines = 3    // blocks with more lines are not headings, whatever their size
+	maxHeadingLevel = 6
+)
+
+// A fontStyle is the appearance of a font, as far as Markdown can show it.
+type fontStyle struct {
+	bold, italic, mono bool
+}
+
+// An mdPage is the text of a page, analyzed for conversion to Markdown.
+type mdPage struct {
+	blocks []Block // the blocks of text outside the tables
+	box    Rect    // the crop box, in upright space
+	tables []Table
+	styles map[string]fontStyle // by font name, as in Text.Font
+}
+
+// A markdown converts analyzed pages to Markdown.
+type markdown struct {
+	w       *bufio.Writer
+	styles  map[string]fontStyle
+	levels  map[float64]int // heading levels by font size
+	outline map[string]int  // outline depths by folded title
+}
+
+// WriteMarkdown writes the text of the document to w as Markdown.
+//
+// The text of each page is arranged into blocks as by Page.Layout.
+// A block of at most three lines set larger than the body text, the
+// size in which most of the document's text is set, is a heading. Its
+// level is the rank of its size among the sizes of the headings, unless
+// its text is the title of an entry in the document outline, in which
+// case the entry's depth gives the level; outline titles also make
+// headings of blocks set in the body size.
+//
+// Lines that begin with a bullet, or with a number or letter followed by
+// a period or parenthesis, are list items, nested by their indentation.
+// Blocks set entirely in monospaced fonts are code blocks, laid out as by
+// Page.GetLayoutText. Words are emphasized when set in bold or italic
+// fonts, as told by the fonts' descriptors or names, and words in
+// monospaced fonts are code spans. The tables that Page.Tables finds
+// become tables, their first row taken as the header.
+//
+// Since heading levels depend on the font sizes used throughout the
+// document, WriteMarkdown lays out every page before it writes anything,
+// and so holds the text of the whole document in memory.
+func (r *Reader) WriteMarkdown(w io.Writer) error {
+	var pages []mdPage
+	err := r.WalkPages(func(i int, p Page) error {
+		page, err := markdownPage(p)
+		if err != nil {
+			return fmt.Errorf("page %d: %v", i, err)
+		}
+		pages = append(pages, page)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Find the body size, weighting sizes by the characters set in them,
+	// and rank the larger sizes of the blocks that may be headings.
+	weight := make(map[float64]int)
+	for _, page := range pages {
+		for _, b := range page.blocks {
+			for _, l := range b.Lines {
+				for _, wd := range l.Words {
+					weight[roundSize(wd.FontSize)] += utf8.RuneCountInString(wd.S)
+				}
+			}
+		}
+	}
+	body, most := 0.0, 0
+	for size, n := range weight {
+		if n > most || n == most && size < body {
+			body, most = size, n
+		}
+	}
+	var sizes []float64
+	seen := make(map[float64]bool)
+	for _, page := range pages {
+		for _, b := range page.blocks {
+			if size := blockSize(b); len(b.Lines) <= maxHeadingLines && size >= body*headingRatio && !seen[size] {
+				seen[size] = true
+				sizes = append(sizes, size)
+			}
+		}
+	}
+	sort.Sort(sort.Reverse(sort.Float64Slice(sizes)))
+	m := &markdown{
+		w:       bufio.NewWriter(w),
+		levels:  make(map[float64]int),
+		outline: make(map[string]int),
+	}
+	for i, size := range sizes {
+		m.levels[size] = i + 1
+		if i >= maxHeadingLevel {
+			m.levels[size] = maxHeadingLevel
+		}
+	}
+	outlineDepths(r.Outline(), 0, m.outline)
+
+	for _, page := range pages {
+		m.styles = page.styles
+		tables := page.tables
+		for _, b := range page.blocks {
+			for len(tables) > 0 && tables[0].Rect.Max.Y >= b.Rect.Max.Y {
+				m.table(tables[0])
+				tables = tables[1:]
+			}
+			m.block(b, page.box)
+		}
+		for _, t := range tables {
+			m.table(t)
+		}
+	}
+	return m.w.Flush()
+}
+
+// markdownPage analyzes the text of p, in upright space,
+// recovering from the panics of a malformed content stream.
+func markdownPage(p Page) (page mdPage, err error) {
+	p = p.fixOptions()
+	defer func() {
+		if r := recover(); r != nil {
+			page = mdPage{}
+			err = errors.New(fmt.Sprint(r))
+		}
+	}()
+	c := p.content(nil)
+	ws := p.extractOptions().finishWords(words(c.Text))
+	page.tables = findTables(c.rules, ws)
+	var rest []Word
+	for _, w := range ws {
+		in := false
+		for _, t := range page.tables {
+			in = in || contains(t.Rect, center(w.Rect))
+		}
+		if !in {
+			rest = append(rest, w)
+		}
+	}
+	page.blocks = layout(rest).Blocks
+	page.box = p.uprightBox()
+	page.styles = make(map[string]fontStyle)
+	fontStyles(p.Resources(), page.styles, 0)
+	return page, nil
+}
+
+// fontStyles records the styles of the fonts in the resources res,
+// and in the resources of the forms they hold, by font name.
+func fontStyles(res Value, styles map[string]fontStyle, depth int) {
+	fonts := res.Key("Font")
+	for _, name := range fonts.Keys() {
+		f := Font{fonts.Key(name), nil}
+		base := f.BaseFont()
+		if i := strings.Index(base, "+"); i >= 0 { // as in Text.Font, without the subset tag
+			base = base[i+1:]
+		}
+		styles[base] = f.style()
+	}
+	if depth >= maxFormDepth {
+		return
+	}
+	xobjs := res.Key("XObject")
+	for _, name := range xobjs.Keys() {
+		if x := xobjs.Key(name); isForm(x) {
+			fontStyles(x.Key("Resources"), styles, depth+1)
+		}
+	}
+}
+
+// style returns the style of f, from its font descriptor
+// or built-in metrics and its name.
+func (f Font) style() fontStyle {
+	s := nameStyle(f.BaseFont())
+	desc := f.V.Key("FontDescriptor")
+	if f.V.Key("Subtype").Name() == "Type0" {
+		desc = f.V.Key("DescendantFonts").Index(0).Key("FontDescriptor")
+	}
+	flags := int(desc.Key("Flags").Int64())
+	if std := f.standardFont(); std != nil && desc.Kind() != Dict {
+		flags = std.flags
+	}
+	s.mono = s.mono || flags&fontFixedPitch != 0
+	s.italic = s.italic || flags&fontItalic != 0 || desc.Key("ItalicAngle").Float64() != 0
+	s.bold = s.bold || flags&fontForceBold != 0 || desc.Key("FontWeight").Float64() >= 600
+	return s
+}
+
+// nameStyle guesses the style of the font with the given name,
+// such as ABCDEF+Arial-BoldItalicMT, from the words in it.
+func nameStyle(name string) fontStyle {
+	if i := strings.Index(name, "+"); i >= 0 {
+		name = name[i+1:]
+	}
+	name = strings.ToLower(name)
+	has := func(parts ...string) bool {
+		for _, p := range parts {
+			if strings.Contains(name, p) {
+				return true
+			}
+		}
+		return false
+	}
+	return fontStyle{
+		bold:   has("bold", "black", "heavy", "demi"),
+		italic: has("italic", "oblique"),
+		mono:   has("courier", "mono", "consolas", "code"),
+	}
+}
+
+// outlineDepths records the depths of the entries of the outline o,
+// whose children are at the given depth plus one, by folded title.
+func outlineDepths(o Outline, depth int, depths map[string]int) {
+	if depth > 0 && o.Title != "" {
+		if _, ok := depths[foldTitle(o.Title)]; !ok {
+			depths[foldTitle(o.Title)] = depth
+		}
+	}
+	if depth >= maxStructDepth {
+		return
+	}
+	for _, c := range o.Child {
+		outlineDepths(c, depth+1, depths)
+	}
+}
+
+// foldTitle folds case and white space in the title s, for comparison.
+func foldTitle(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+}
+
+// roundSize rounds a font size to the nearest half point,
+// so that sizes differing by rounding errors compare equal.
+func roundSize(size float64) float64 {
+	return math.Floor(math.Abs(size)*2+0.5) / 2
+}
+
+// blockSize returns the font size of the most characters in b.
+func blockSize(b Block) float64 {
+	weight := make(map[float64]int)
+	size, most := 0.0, 0
+	for _, l := range b.Lines {
+		for _, w := range l.Words {
+			s := roundSize(w.FontSize)
+			if weight[s] += utf8.RuneCountInString(w.S); weight[s] > most {
+				size, most = s, weight[s]
+			}
+		}
+	}
+	return size
+}
+
+// style returns the style of the font with the given name.
+func (m *markdown) style(font string) fontStyle {
+	if s, ok := m.styles[font]; ok {
+		return s
+	}
+	return nameStyle(font)
+}
+
+// block writes the block b, on a page with the crop box box,
+// as a heading, a code block, or paragraphs and lists.
+func (m *markdown) block(b Block, box Rect) {
+	var all []Word
+	var text []string
+	for _, l := range b.Lines {
+		for _, w := range l.Words {
+			all = append(all, w)
+			text = append(text, w.S)
+		}
+	}
+	if len(all) == 0 {
+		return
+	}
+
+	level, ok := m.outline[foldTitle(strings.Join(text, " "))]
+	if !ok && len(b.Lines) <= maxHeadingLines {
+		level = m.levels[blockSize(b)]
+	}
+	if level > 0 {
+		if level > maxHeadingLevel {
+			level = maxHeadingLevel
+		}
+		for i := range text {
+			text[i] = escapeMarkdown(text[i])
+		}
+		fmt.Fprintf(m.w, "%s %s\n\n", strings.Repeat("#", level), strings.Join(text, " "))
+		return
+	}
+
+	code := true
+	for _, w := range all {
+		code = code && m.style(w.Font).mono
+	}
+	if code {
+		fmt.Fprintf(m.w, "```\n%s```\n\n", layoutText(all, box))
+		return
+	}
+
+	// Gather the lines into paragraphs and list items. A line
+	// indented past the marker of a list item continues the item.
+	var (
+		out     []string  // the Markdown lines of the block
+		para    []string  // the lines of the current paragraph
+		markers []float64 // the X coordinates of the markers of the open lists
+		inList  bool      // whether the previous line was in a list item
+	)
+	for _, l := range b.Lines {
+		ws := l.Words
+		tol := math.Abs(ws[0].FontSize) / 2
+		if prefix, keep := listMarker(ws[0].S); prefix != "" && len(ws) > 1 {
+			if len(para) > 0 {
+				out = append(out, strings.Join(para, " "), "")
+				para = nil
+			}
+			x := ws[0].X
+			for len(markers) > 0 && markers[len(markers)-1] > x+tol {
+				markers = markers[:len(markers)-1]
+			}
+			if len(markers) == 0 || x > markers[len(markers)-1]+tol {
+				markers = append(markers, x)
+			}
+			if !keep {
+				ws = ws[1:]
+			}
+			out = append(out, strings.Repeat("    ", len(markers)-1)+prefix+m.inline(ws))
+			inList = true
+			continue
+		}
+		if inList && ws[0].X > markers[len(markers)-1]+tol {
+			out[len(out)-1] += " " + m.inline(ws)
+			continue
+		}
+		if inList {
+			out = append(out, "")
+			markers, inList = nil, false
+		}
+		para = append(para, m.inline(ws))
+	}
+	if len(para) > 0 {
+		out = append(out, strings.Join(para, " "))
+	}
+	fmt.Fprintf(m.w, "%s\n\n", strings.Join(out, "\n"))
+}
+
+var (
+	bullets  = "•◦▪▫●○■□‣⁃–—-*·►➢✓✔"
+	numbered = regexp.MustCompile(`^([0-9]{1,3})[.)]$`)
+	labelled = regexp.MustCompile(`^([a-zA-Z]|[ivxIVX]{2,4})[.)]$`)
+)
+
+// listMarker returns the Markdown list item prefix for a line that
+// begins with the word s, or "" if s does not mark a list item.
+// It reports whether s is to be kept, as a label that Markdown
+// lists cannot number, such as "a)".
+func listMarker(s string) (prefix string, keep bool) {
+	if r, n := utf8.DecodeRuneInString(s); n == len(s) && strings.ContainsRune(bullets, r) {
+		return "- ", false
+	}
+	if m := numbered.FindStringSubmatch(s); m != nil {
+		return m[1] + ". ", false
+	}
+	if labelled.MatchString(s) {
+		return "- ", true
+	}
+	return "", false
+}
+
+// inline returns the words ws as Markdown, with emphasis and code spans.
+func (m *markdown) inline(ws []Word) string {
+	var out []string
+	for i := 0; i < len(ws); {
+		s := m.style(ws[i].Font)
+		j := i + 1
+		for j < len(ws) && m.style(ws[j].Font) == s {
+			j++
+		}
+		var text []string
+		for _, w := range ws[i:j] {
+			if s.mono {
+				text = append(text, w.S)
+			} else {
+				text = append(text, escapeMarkdown(w.S))
+			}
+		}
+		run := strings.Join(text, " ")
+		switch {
+		case s.mono && strings.Contains(run, "`"):
+			run = "`` " + run + " ``"
+		case s.mono:
+			run = "`" + run + "`"
+		case s.bold && s.italic:
+			run = "***" + run + "***"
+		case s.bold:
+			run = "**" + run + "**"
+		case s.italic:
+			run = "*" + run + "*"
+		}
+		out = append(out, run)
+		i = j
+	}
+	return strings.Join(out, " ")
+}
+
+// markdownEscaper escapes the characters that Markdown would take as markup.
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", `*`, `\*`, `_`, `\_`, `[`, `\[`, `]`, `\]`, `#`, `\#`, `<`, `\<`, `>`, `\>`, `|`, `\|`,
+)
+
+func escapeMarkdown(s string) string {
+	return markdownEscaper.Replace(s)
+}
+
+// table writes the table t as a Markdown table. The text of merged
+// cells is put in their first column, and lines of cells are joined.
+func (m *markdown) table(t Table) {
+	if len(t.Rows) == 0 || t.Cols == 0 {
+		return
+	}
+	grid := make([][]string, len(t.Rows))
+	for i := range grid {
+		grid[i] = make([]string, t.Cols)
+	}
+	for _, row := range t.Rows {
+		for _, c := range row.Cells {
+			if c.Row < len(grid) && c.Col < t.Cols {
+				grid[c.Row][c.Col] = escapeMarkdown(strings.Join(strings.Fields(c.Text), " "))
+			}
+		}
+	}
+	for i, row := range grid {
+		fmt.Fprintf(m.w, "| %s |\n", strings.Join(row, " | "))
+		if i == 0 {
+			fmt.Fprintf(m.w, "|%s\n", strings.Repeat(" --- |", t.Cols))
+		}
+	}
+	m.w.WriteString("\n")
+}
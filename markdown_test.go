@@ -0,0 +1,64 @@
+package pdf
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+func TestMarkdown(t *testing.T) {
+	r := newTestPDF(t,
+		`<< /Type /Catalog /Pages 2 0 R /Outlines 5 0 R >>`,
+		`<< /Type /Pages /Kids [3 0 R] /Count 1 >>`,
+		`<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 4 0 R
+		   /Resources << /Font << /F1 << /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>
+		                          /F2 << /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold >>
+		                          /F3 << /Type /Font /Subtype /TrueType /BaseFont /ABCDEF+Serif
+		                                 /FontDescriptor << /Flags 96 /ItalicAngle -12 >> >>
+		                          /F4 << /Type /Font /Subtype /Type1 /BaseFont /Courier >> >> >> >>`,
+		`stream
+BT
+/F2 24 Tf 1 0 0 1 72 740 Tm (Report) Tj
+/F1 18 Tf 1 0 0 1 72 700 Tm (Introduction) Tj
+/F1 10 Tf 1 0 0 1 72 670 Tm (This is) Tj /F2 10 Tf ( bold) Tj /F1 10 Tf ( and) Tj
+/F3 10 Tf 1 0 0 1 72 658 Tm (italic) Tj /F1 10 Tf ( text, a_b.) Tj
+/F1 10 Tf 1 0 0 1 72 630 Tm (Details) Tj
+1 0 0 1 72 600 Tm (\267) Tj 1 0 0 1 84 600 Tm (First item) Tj
+1 0 0 1 84 588 Tm (continued) Tj
+1 0 0 1 96 576 Tm (1.) Tj 1 0 0 1 108 576 Tm (Nested) Tj
+1 0 0 1 72 564 Tm (\267) Tj 1 0 0 1 84 564 Tm (Second) Tj
+/F4 10 Tf 1 0 0 1 72 530 Tm (x := 1) Tj 1 0 0 1 72 518 Tm (if x {) Tj 1 0 0 1 84 506 Tm (y\(\)) Tj 1 0 0 1 72 494 Tm (}) Tj
+ET`,
+		`<< /First 6 0 R /Last 6 0 R >>`,
+		`<< /Title (Introduction) /Parent 5 0 R /First 7 0 R /Last 7 0 R >>`,
+		`<< /Title (Details) /Parent 6 0 R >>`,
+	)
+	var buf bytes.Buffer
+	assert.Equal(t, nil, r.WriteMarkdown(&buf))
+	assert.Equal(t, "# Report\n\n"+
+		"# Introduction\n\n"+
+		"This is **bold** and *italic* text, a\\_b.\n\n"+
+		"## Details\n\n"+
+		"- First item continued\n"+
+		"    1. Nested\n"+
+		"- Second\n\n"+
+		"```\nx := 1\nif x {\n  y()\n}\n```\n\n", buf.String())
+}
+
+func TestMarkdownTable(t *testing.T) {
+	r := newTestPDF(t,
+		`<< /Type /Catalog /Pages 2 0 R >>`,
+		`<< /Type /Pages /Kids [3 0 R] /Count 1 >>`,
+		`<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 4 0 R
+		   /Resources << /Font << /F1 << /Type /Font /Subtype /Type1 /BaseFont /Helvetica >> >> >> >>`,
+		`stream
+BT /F1 10 Tf 1 0 0 1 72 460 Tm (Prices:) Tj ET
+72 400 200 40 re S 172 400 m 172 440 l S 72 420 m 272 420 l S
+BT /F1 10 Tf 1 0 0 1 80 425 Tm (Item) Tj 1 0 0 1 180 425 Tm (Cost) Tj
+1 0 0 1 80 405 Tm (Tea) Tj 1 0 0 1 180 405 Tm (2|3) Tj ET`,
+	)
+	var buf bytes.Buffer
+	assert.Equal(t, nil, r.WriteMarkdown(&buf))
+	assert.Equal(t, "Prices:\n\n| Item | Cost |\n| --- | --- |\n| Tea | 2\\|3 |\n\n", buf.String())
+}
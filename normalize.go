@@ -0,0 +1,202 @@
+// Copyright 2014 The Go Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pdf
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// A NormalForm is a Unicode normalization form.
+type NormalForm int
+
+const (
+	NoNormalForm NormalForm = iota // leave the text as it is
+	NFC                            // compose characters, such as letters and separately shown accents
+	NFKC                           // compose characters and replace compatibility characters, such as ligatures and full-width forms
+)
+
+// ligatures maps the Latin ligature characters to the letters they join.
+var ligatures = map[rune]string{
+	0xFB00: "ff",
+	0xFB01: "fi",
+	0xFB02: "fl",
+	0xFB03: "ffi",
+	0xFB04: "ffl",
+	0xFB05: "ſt",
+	0xFB06: "st",
+}
+
+// normalize applies the character-level options of o to s.
+func (o ExtractOptions) normalize(s string) string {
+	if o.ExpandLigatures && strings.IndexFunc(s, isLigature) >= 0 {
+		var b strings.Builder
+		for _, r := range s {
+			if l, ok := ligatures[r]; ok {
+				b.WriteString(l)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+		s = b.String()
+	}
+	if o.RemoveSoftHyphens {
+		s = strings.Replace(s, "\u00AD", "", -1)
+	}
+	switch o.NormalForm {
+	case NFC:
+		s = norm.NFC.String(s)
+	case NFKC:
+		s = norm.NFKC.String(s)
+	}
+	return s
+}
+
+func isLigature(r rune) bool {
+	_, ok := ligatures[r]
+	return ok
+}
+
+// normalizeText applies the character-level options of o to the glyphs
+// in text. Glyphs left with no text are dropped. When composing, a glyph
+// showing only combining marks, such as an accent placed over the
+// previous letter, is merged into the glyph before it.
+func (o ExtractOptions) normalizeText(text []Text) []Text {
+	if !o.ExpandLigatures && !o.RemoveSoftHyphens && o.NormalForm == NoNormalForm {
+		return text
+	}
+	out := text[:0]
+	prev := -1
+	for _, t := range text {
+		if t.S == "\n" {
+			out = append(out, t)
+			continue
+		}
+		t.S = o.normalize(t.S)
+		if t.S == "" {
+			continue
+		}
+		if o.NormalForm != NoNormalForm && prev >= 0 && combiningOnly(t.S) {
+			out[prev].S = o.normalize(out[prev].S + t.S)
+			continue
+		}
+		prev = len(out)
+		out = append(out, t)
+	}
+	return out
+}
+
+// combiningOnly reports whether s consists only of combining marks.
+func combiningOnly(s string) bool {
+	for _, r := range s {
+		if !unicode.Is(unicode.Mn, r) {
+			return false
+		}
+	}
+	return true
+}
+
+// dehyphenate joins words hyphenated across the end of a line: a word
+// ending a line in a hyphen, followed on the next line by a word starting
+// with a lowercase letter. A soft hyphen always marks such a break.
+// The joined word keeps the position of its first part, and its
+// bounding box covers both parts.
+func dehyphenate(words []Word) []Word {
+	var out []Word
+	for i := 0; i < len(words); i++ {
+		w := words[i]
+		for i+1 < len(words) {
+			next := words[i+1]
+			if sameBaseline(w, next) {
+				break
+			}
+			last, n := utf8.DecodeLastRuneInString(w.S)
+			first, _ := utf8.DecodeRuneInString(next.S)
+			if n == len(w.S) || !(last == '\u00AD' || (last == '-' || last == '\u2010') && unicode.IsLower(first)) {
+				break
+			}
+			w.S = w.S[:len(w.S)-n] + next.S
+			w.Rect = w.Rect.union(next.Rect)
+			i++
+		}
+		out = append(out, w)
+	}
+	return out
+}
+
+// finishWords applies the options of o to words made from raw glyphs.
+func (o ExtractOptions) finishWords(words []Word) []Word {
+	if o.Dehyphenate {
+		words = dehyphenate(words)
+	}
+	out := words[:0]
+	for _, w := range words {
+		if w.S = o.normalize(w.S); w.S != "" {
+			out = append(out, w)
+		}
+	}
+	return out
+}
+
+// finishTexts applies the character-level options of o to the texts
+// of a row or column, dropping texts that it empties.
+func (o ExtractOptions) finishTexts(text []Text) []Text {
+	out := text[:0]
+	for _, t := range text {
+		empty := t.S == ""
+		if t.S = o.normalize(t.S); t.S != "" || empty {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// dehyphenateRows joins words hyphenated across consecutive rows,
+// moving the rest of the word from the start of the next row
+// to the end of the row before it.
+func dehyphenateRows(rows Rows) {
+	for i := 0; i+1 < len(rows); i++ {
+		last := lastText(rows[i].Content)
+		first := firstText(rows[i+1].Content)
+		if last == nil || first == nil {
+			continue
+		}
+		r, n := utf8.DecodeLastRuneInString(last.S)
+		start := strings.TrimLeftFunc(first.S, unicode.IsSpace)
+		c, _ := utf8.DecodeRuneInString(start)
+		if n == 0 || !(r == '\u00AD' || (r == '-' || r == '\u2010') && unicode.IsLower(c)) {
+			continue
+		}
+		end := strings.IndexFunc(start, unicode.IsSpace)
+		if end < 0 {
+			end = len(start)
+		}
+		last.S = last.S[:len(last.S)-n] + start[:end]
+		first.S = strings.TrimLeftFunc(start[end:], unicode.IsSpace)
+	}
+}
+
+// firstText returns the first text in row that is not empty, or nil.
+func firstText(row TextHorizontal) *Text {
+	for i := range row {
+		if strings.TrimSpace(row[i].S) != "" {
+			return &row[i]
+		}
+	}
+	return nil
+}
+
+// lastText returns the last text in row that is not empty, or nil.
+func lastText(row TextHorizontal) *Text {
+	for i := len(row) - 1; i >= 0; i-- {
+		if strings.TrimSpace(row[i].S) != "" {
+			return &row[i]
+		}
+	}
+	return nil
+}
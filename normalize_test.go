@@ -0,0 +1,71 @@
+package pdf
+
+import (
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		opts ExtractOptions
+		in   string
+		out  string
+	}{
+		{ExtractOptions{}, "ﬁnd of\u00adten", "ﬁnd of\u00adten"},
+		{ExtractOptions{ExpandLigatures: true}, "ﬁnd ﬄ", "find ffl"},
+		{ExtractOptions{RemoveSoftHyphens: true}, "of\u00adten", "often"},
+		{ExtractOptions{NormalForm: NFC}, "e\u0301", "é"},
+		{ExtractOptions{NormalForm: NFKC}, "ﬁ Ａ", "fi A"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.out, tt.opts.normalize(tt.in))
+	}
+
+	// An accent shown as its own glyph is composed with the letter before it.
+	text := []Text{{S: "e"}, {S: "\u0301"}, {S: "\n"}, {S: "\u00ad"}}
+	text = ExtractOptions{NormalForm: NFC, RemoveSoftHyphens: true}.normalizeText(text)
+	assert.Equal(t, []Text{{S: "é"}, {S: "\n"}}, text)
+}
+
+func TestDehyphenate(t *testing.T) {
+	r := newTestPDF(t,
+		`<< /Type /Catalog /Pages 2 0 R >>`,
+		`<< /Type /Pages /Kids [3 0 R] /Count 1 >>`,
+		`<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 4 0 R
+		   /Resources << /Font << /F1 << /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >> >> >> >>`,
+		`stream
+BT /F1 12 Tf
+1 0 0 1 72 720 Tm (An exam-) Tj
+1 0 0 1 72 706 Tm (ple of Ger-) Tj
+1 0 0 1 72 692 Tm (Many and of\255) Tj
+1 0 0 1 72 678 Tm (ten) Tj
+ET`,
+	)
+	p := r.Page(1)
+	text, err := p.GetPlainText(nil)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "An exam-\nple of Ger-\nMany and of\u00ad\nten\n", text)
+
+	r.SetExtractOptions(ExtractOptions{Dehyphenate: true})
+	text, err = p.GetPlainText(nil)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "An example\nof Ger-\nMany and often\n", text)
+
+	words := p.Words()
+	assert.Equal(t, "example", words[1].S)
+	assert.Equal(t, 72.0, words[1].Rect.Min.X)
+	assert.Equal(t, 720.0, words[1].Y)
+
+	rows, err := p.GetTextByRow()
+	assert.Equal(t, nil, err)
+	var shown []string
+	for _, row := range rows {
+		for _, t := range row.Content {
+			if t.S != "" {
+				shown = append(shown, t.S)
+			}
+		}
+	}
+	assert.Equal(t, []string{"An example", "of Ger-", "Many and often"}, shown)
+}
@@ -0,0 +1,154 @@
+// Copyright 2014 The Go Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Optional content. See PDF 32000-1:2008, §8.11.
+
+package pdf
+
+// A Layer is an optional content group: content that a viewer
+// may show or hide, such as a watermark or a translation.
+type Layer struct {
+	Name    string
+	Visible bool // whether the layer is shown in the configuration selected by the extraction options
+}
+
+// Layers returns the document's optional content groups,
+// in the order of the OCProperties OCGs array.
+func (r *Reader) Layers() []Layer {
+	ocgs := r.Trailer().Key("Root").Key("OCProperties").Key("OCGs")
+	oc := r.optionalContent(r.extractOptions())
+	var out []Layer
+	for i := 0; i < ocgs.Len(); i++ {
+		g := ocgs.Index(i)
+		if g.Kind() != Dict {
+			continue
+		}
+		out = append(out, Layer{Name: g.Key("Name").Text(), Visible: oc.visible(g)})
+	}
+	return out
+}
+
+// optionalContent records which optional content groups are hidden.
+// A nil *optionalContent shows all content.
+type optionalContent struct {
+	off map[objptr]bool
+}
+
+// optionalContent returns the visibility of the document's optional
+// content groups in the configuration the extraction options opts select:
+// the named configuration from the Configs array, or else the default
+// configuration D. Each configuration starts from its own BaseState,
+// which is ON unless it says otherwise. It returns nil if the document
+// has no optional content or the options include all layers.
+func (r *Reader) optionalContent(opts ExtractOptions) *optionalContent {
+	props := r.Trailer().Key("Root").Key("OCProperties")
+	if opts.AllLayers || props.Kind() != Dict {
+		return nil
+	}
+	config := props.Key("D")
+	if opts.OptionalContentConfig != "" {
+		configs := props.Key("Configs")
+		for i := 0; i < configs.Len(); i++ {
+			if c := configs.Index(i); c.Key("Name").Text() == opts.OptionalContentConfig {
+				config = c
+				break
+			}
+		}
+	}
+
+	oc := &optionalContent{off: make(map[objptr]bool)}
+	if config.Key("BaseState").Name() == "OFF" {
+		ocgs := props.Key("OCGs")
+		for i := 0; i < ocgs.Len(); i++ {
+			oc.off[ocgs.Index(i).ptr] = true
+		}
+	}
+	on := config.Key("ON")
+	for i := 0; i < on.Len(); i++ {
+		delete(oc.off, on.Index(i).ptr)
+	}
+	off := config.Key("OFF")
+	for i := 0; i < off.Len(); i++ {
+		oc.off[off.Index(i).ptr] = true
+	}
+	return oc
+}
+
+// visible reports whether the content marked with v, an optional content
+// group or membership dictionary, is shown.
+func (oc *optionalContent) visible(v Value) bool {
+	if oc == nil || v.Kind() != Dict {
+		return true
+	}
+	if v.Key("Type").Name() != "OCMD" {
+		return !oc.off[v.ptr]
+	}
+	if ve := v.Key("VE"); ve.Kind() == Array {
+		return oc.eval(ve, 0)
+	}
+
+	// Without a visibility expression, the policy P
+	// combines the states of the groups in OCGs.
+	var groups []Value
+	forEachKid(v.Key("OCGs"), func(g Value) {
+		if g.Kind() == Dict {
+			groups = append(groups, g)
+		}
+	})
+	if len(groups) == 0 {
+		return true
+	}
+	on := 0
+	for _, g := range groups {
+		if !oc.off[g.ptr] {
+			on++
+		}
+	}
+	switch v.Key("P").Name() {
+	case "AllOn":
+		return on == len(groups)
+	case "AnyOff":
+		return on < len(groups)
+	case "AllOff":
+		return on == 0
+	}
+	return on > 0 // AnyOn
+}
+
+// eval evaluates the visibility expression e, an array whose first
+// element is And, Or or Not and whose others are optional content
+// groups or nested expressions.
+func (oc *optionalContent) eval(e Value, depth int) bool {
+	if e.Kind() == Dict {
+		return !oc.off[e.ptr]
+	}
+	if e.Kind() != Array || depth > maxStructDepth {
+		return true
+	}
+	op := e.Index(0).Name()
+	if op == "Not" {
+		return !oc.eval(e.Index(1), depth+1)
+	}
+	for i := 1; i < e.Len(); i++ {
+		v := oc.eval(e.Index(i), depth+1)
+		if op == "Or" && v {
+			return true
+		}
+		if op == "And" && !v {
+			return false
+		}
+	}
+	return op == "And"
+}
+
+// enter marks the marked-content sequence m as hidden if it
+// or a sequence enclosing it is optional content that is hidden.
+func (oc *optionalContent) enter(m *MarkedContent) {
+	m.hidden = m.Parent.isHidden() || m.Tag == "OC" && !oc.visible(m.Properties)
+}
+
+// isHidden reports whether m is hidden optional content.
+func (m *MarkedContent) isHidden() bool {
+	return m != nil && m.hidden
+}
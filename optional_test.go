@@ -0,0 +1,63 @@
+package pdf
+
+import (
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+func TestOptionalContent(t *testing.T) {
+	r := newTestPDF(t,
+		`<< /Type /Catalog /Pages 2 0 R
+		   /OCProperties << /OCGs [5 0 R 6 0 R 7 0 R]
+		                    /D << /OFF [6 0 R] >>
+		                    /Configs [<< /Name (French) /ON [6 0 R] /OFF [5 0 R] >>
+		                              << /Name (Screen) /OFF [7 0 R] >>] >> >>`,
+		`<< /Type /Pages /Kids [3 0 R] /Count 1 >>`,
+		`<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 4 0 R
+		   /Resources << /Font << /F1 << /Type /Font /Subtype /Type1 /BaseFont /Helvetica >> >>
+		                 /Properties << /EN 5 0 R /FR 6 0 R
+		                                /Either << /Type /OCMD /OCGs [5 0 R 6 0 R] >>
+		                                /NotPrint << /Type /OCMD /VE [/Not 7 0 R] >> >> >> >>`,
+		`stream
+BT /F1 12 Tf
+1 0 0 1 72 700 Tm /OC /EN BDC (Hello) Tj EMC
+1 0 0 1 72 680 Tm /OC /FR BDC (Bonjour) Tj /Span BMC ( Salut) Tj EMC EMC
+1 0 0 1 72 660 Tm /OC /Either BDC (Both) Tj EMC
+1 0 0 1 72 640 Tm /OC /NotPrint BDC (Screen) Tj EMC
+ET
+/OC /FR BDC 0 0 10 10 re f EMC`,
+		`<< /Type /OCG /Name (English) >>`,
+		`<< /Type /OCG /Name (French) >>`,
+		`<< /Type /OCG /Name (Print) >>`,
+	)
+	p := r.Page(1)
+	text, err := p.GetPlainText(nil)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "Hello\nBoth\n", text)
+	assert.Equal(t, 0, len(p.Content().Rect))
+	assert.Equal(t, []Layer{{"English", true}, {"French", false}, {"Print", true}}, r.Layers())
+
+	r.SetExtractOptions(ExtractOptions{OptionalContentConfig: "French"})
+	text, _ = p.GetPlainText(nil)
+	assert.Equal(t, "Bonjour Salut\nBoth\n", text)
+
+	// Without a BaseState, a configuration starts with every group on,
+	// including those D turns off.
+	r.SetExtractOptions(ExtractOptions{OptionalContentConfig: "Screen"})
+	text, _ = p.GetPlainText(nil)
+	assert.Equal(t, "Hello\nBonjour Salut\nBoth\nScreen\n", text)
+	assert.Equal(t, []Layer{{"English", true}, {"French", true}, {"Print", false}}, r.Layers())
+
+	r.SetExtractOptions(ExtractOptions{AllLayers: true})
+	text, _ = p.GetPlainText(nil)
+	assert.Equal(t, "Hello\nBonjour Salut\nBoth\nScreen\n", text)
+	assert.Equal(t, 1, len(p.Content().Rect))
+
+	rows, err := p.GetTextByRow()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 4, len(rows))
+	r.SetExtractOptions(ExtractOptions{})
+	rows, _ = p.GetTextByRow()
+	assert.Equal(t, 2, len(rows))
+}
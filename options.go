@@ -0,0 +1,100 @@
+// Copyright 2014 The Go Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pdf
+
+// ExtractOptions control how text is extracted from a document's pages.
+// The zero value extracts the text of the visible layers as the fonts encode it.
+type ExtractOptions struct {
+	// ExpandLigatures replaces ligature characters, such as U+FB01 (ﬁ),
+	// with the letters they join.
+	ExpandLigatures bool
+
+	// NormalForm is the Unicode normalization form applied to the text.
+	NormalForm NormalForm
+
+	// RemoveSoftHyphens deletes soft hyphens (U+00AD).
+	RemoveSoftHyphens bool
+
+	// Dehyphenate joins words hyphenated across the end of a line,
+	// dropping the hyphen. It applies where text is joined into words and
+	// lines: Words, Layout, GetPlainText, GetTextByRow and GetTextByColumn.
+	Dehyphenate bool
+
+	// OptionalContentConfig names the optional content configuration,
+	// from the document's OCProperties Configs array, that decides which
+	// layers are visible. Content in hidden layers is not extracted.
+	// The empty string selects the default configuration.
+	OptionalContentConfig string
+
+	// AllLayers extracts the content of all layers, visible or hidden.
+	AllLayers bool
+
+	// DisplaySpace gives coordinates in the space of the page as it is
+	// displayed, rather than in user space: the origin is at the top left
+	// corner of the crop box and Y increases down the page, the page's
+	// Rotate and UserUnit are applied, and content outside the crop box is
+	// left out. Text is then also read along its displayed lines. It
+	// applies to Content, Words, Layout, Tables, Search, GetTextByRow and
+	// GetTextByColumn.
+	DisplaySpace bool
+
+	// DPI is the resolution of display space, in pixels per inch. Zero means 72,
+	// which gives coordinates, and font sizes, in points.
+	DPI float64
+
+	// Annotations extracts the text of the normal appearances of the
+	// page's annotations, such as stamps, comments shown on the page and
+	// filled form fields, along with the page's own content. Annotations
+	// that are hidden, or shown only when printed, are left out.
+	Annotations bool
+}
+
+// SetExtractOptions sets the options used by the text extraction
+// methods of the Reader and of its pages. It may be called while other
+// goroutines extract text: each method uses the options set when it
+// begins, and each walk of the pages, as by WalkPages, the options set
+// when the walk begins. To extract a page with other options, without
+// changing the Reader's, use Page.WithExtractOptions.
+func (r *Reader) SetExtractOptions(opts ExtractOptions) {
+	r.optsMu.Lock()
+	defer r.optsMu.Unlock()
+	r.opts = opts
+}
+
+// extractOptions returns the extraction options of r, which may be nil.
+func (r *Reader) extractOptions() ExtractOptions {
+	if r == nil {
+		return ExtractOptions{}
+	}
+	r.optsMu.RLock()
+	defer r.optsMu.RUnlock()
+	return r.opts
+}
+
+// WithExtractOptions returns a copy of p whose text extraction
+// methods use opts in place of the Reader's extraction options.
+func (p Page) WithExtractOptions(opts ExtractOptions) Page {
+	p.opts = &opts
+	return p
+}
+
+// extractOptions returns the extraction options of p.
+func (p Page) extractOptions() ExtractOptions {
+	if p.opts != nil {
+		return *p.opts
+	}
+	return p.V.r.extractOptions()
+}
+
+// fixOptions returns a copy of p that keeps the extraction options
+// in effect now, so that an operation that consults them more than
+// once sees the same options throughout.
+func (p Page) fixOptions() Page {
+	if p.opts == nil {
+		opts := p.V.r.extractOptions()
+		p.opts = &opts
+	}
+	return p
+}
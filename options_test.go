@@ -0,0 +1,41 @@
+package pdf
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+func TestPageExtractOptions(t *testing.T) {
+	r := newTestPDF(t,
+		`<< /Type /Catalog /Pages 2 0 R >>`,
+		`<< /Type /Pages /Kids [3 0 R] /Count 1 >>`,
+		`<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 4 0 R
+		   /Resources << /Font << /F1 << /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >> >> >> >>`,
+		`stream
+BT /F1 12 Tf 1 0 0 1 72 720 Tm (An exam-) Tj 1 0 0 1 72 706 Tm (ple) Tj ET`,
+	)
+	p := r.Page(1)
+	joined := p.WithExtractOptions(ExtractOptions{Dehyphenate: true})
+	text, err := joined.GetPlainText(nil)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "An example\n", text)
+	text, err = p.GetPlainText(nil)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "An exam-\nple\n", text)
+
+	// Setting the options while pages are read is safe.
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				r.SetExtractOptions(ExtractOptions{Dehyphenate: j%2 == 0, DisplaySpace: i%2 == 0})
+				p.Words()
+			}
+		}(i)
+	}
+	wg.Wait()
+}
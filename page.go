@@ -13,6 +13,7 @@ import (
 	"image/png"
 	"io"
 	"io/ioutil"
+	"math"
 	"math/bits"
 	"sort"
 	"strings"
@@ -22,6 +23,8 @@ import (
 // The methods interpret a Page dictionary stored in V.
 type Page struct {
 	V Value
+
+	opts *ExtractOptions // the extraction options, if not the Reader's; see WithExtractOptions
 }
 
 // Page returns the page for the given page number.
@@ -50,7 +53,7 @@ Search:
 			}
 			if kid.Key("Type").Name() == "Page" {
 				if num == 0 {
-					return Page{kid}
+					return Page{V: kid}
 				}
 				num--
 			}
@@ -59,6 +62,40 @@ Search:
 	return Page{}
 }
 
+// WalkPages calls fn for each page of the PDF file, in order, with its
+// page number, starting at 1. Unlike calling Page for each number, it
+// walks the page tree once. The pages keep the extraction options set
+// when the walk begins. If fn returns an error, WalkPages stops and
+// returns that error.
+func (r *Reader) WalkPages(fn func(num int, p Page) error) error {
+	opts := r.extractOptions()
+	num := 0
+	seen := make(map[objptr]bool) // the page tree nodes visited, to stop cycles
+	var walk func(node Value) error
+	walk = func(node Value) error {
+		if node.ptr != (objptr{}) {
+			if seen[node.ptr] {
+				return nil
+			}
+			seen[node.ptr] = true
+		}
+		switch node.Key("Type").Name() {
+		case "Pages":
+			kids := node.Key("Kids")
+			for i := 0; i < kids.Len(); i++ {
+				if err := walk(kids.Index(i)); err != nil {
+					return err
+				}
+			}
+		case "Page":
+			num++
+			return fn(num, Page{V: node, opts: &opts})
+		}
+		return nil
+	}
+	return walk(r.Trailer().Key("Root").Key("Pages"))
+}
+
 // NumPage returns the number of pages in the PDF file.
 func (r *Reader) NumPage() int {
 	return int(r.Trailer().Key("Root").Key("Pages").Key("Count").Int64())
@@ -66,26 +103,28 @@ func (r *Reader) NumPage() int {
 
 // GetPlainText returns all the text in the PDF file
 func (r *Reader) GetPlainText() (reader io.Reader, err error) {
-	pages := r.NumPage()
 	var buf bytes.Buffer
-	fonts := make(map[string]*Font)
-	for i := 1; i <= pages; i++ {
-		p := r.Page(i)
-		for _, name := range p.Fonts() { // cache fonts so we don't continually parse charmap
-			if _, ok := fonts[name]; !ok {
-				f := p.Font(name)
-				fonts[name] = &f
-			}
-		}
-		text, err := p.GetPlainText(fonts)
-		if err != nil {
-			return &bytes.Buffer{}, err
-		}
-		buf.WriteString(text)
+	if err := r.WritePlainText(&buf); err != nil {
+		return &bytes.Buffer{}, err
 	}
 	return &buf, nil
 }
 
+// WritePlainText writes all the text in the PDF file to w, as returned
+// by GetPlainText, a page at a time, so that the text of the whole file
+// is never held in memory. A font used on several pages is decoded once.
+func (r *Reader) WritePlainText(w io.Writer) error {
+	caches := make(map[objptr]*fontCache)
+	return r.WalkPages(func(num int, p Page) error {
+		text, err := p.GetPlainText(p.sharedFonts(caches))
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(w, text)
+		return err
+	})
+}
+
 func (p Page) findInherited(key string) Value {
 	for v := p.V; !v.IsNull(); v = v.Key("Parent") {
 		if r := v.Key(key); !r.IsNull() {
@@ -115,14 +154,44 @@ func (p Page) Fonts() []string {
 
 // Font returns the font with the given name associated with the page.
 func (p Page) Font(name string) Font {
-	return Font{p.Resources().Key("Font").Key(name), nil}
+	return Font{p.Resources().Key("Font").Key(name), new(fontCache)}
+}
+
+// sharedFonts returns the page's fonts by name, as passed to
+// GetPlainText. Font names are local to each page, so fonts are shared
+// between pages by object: a font given by an indirect reference whose
+// object has a cache in caches uses it, and adds its cache otherwise.
+func (p Page) sharedFonts(caches map[objptr]*fontCache) map[string]*Font {
+	fonts := make(map[string]*Font)
+	res := p.Resources().Key("Font")
+	d, _ := res.data.(dict)
+	for _, n := range res.Keys() {
+		f := Font{res.Key(n), new(fontCache)}
+		if ptr, ok := d[name(n)].(objptr); ok {
+			if c, ok := caches[ptr]; ok {
+				f.cache = c
+			} else {
+				caches[ptr] = f.cache
+			}
+		}
+		fonts[n] = &f
+	}
+	return fonts
 }
 
 // A Font represent a font in a PDF file.
 // The methods interpret a Font dictionary stored in V.
 type Font struct {
-	V   Value
-	enc TextEncoding
+	V     Value
+	cache *fontCache // shared by copies of the Font; nil disables caching
+}
+
+// A fontCache holds the parts of a font that are expensive
+// to rebuild for every string or glyph shown.
+type fontCache struct {
+	enc    TextEncoding
+	simple *simpleEncoding
+	extent *[2]float64 // ascent and descent, in text space units
 }
 
 // BaseFont returns the font's name (BaseFont property).
@@ -151,43 +220,348 @@ func (f Font) Widths() []float64 {
 	return out
 }
 
+// Subtype returns the font's subtype: Type0, Type1, MMType1, Type3 or TrueType.
+func (f Font) Subtype() string {
+	return f.V.Key("Subtype").Name()
+}
+
 // Width returns the width of the given code point.
+// For a Type0 font, the code is first mapped to a CID by the font's encoding CMap
+// and the width is taken from the descendant CIDFont's W and DW entries.
 func (f Font) Width(code int) float64 {
-	first := f.FirstChar()
-	last := f.LastChar()
-	if code < first || last < code {
-		return 0
+	if f.Subtype() == "Type0" {
+		if m := f.encodingCMap(); m != nil {
+			return f.cidWidth(m.cid(codeString(code, m.space.width(code))))
+		}
+		return f.cidWidth(code)
+	}
+	if widths := f.V.Key("Widths"); widths.Kind() == Array {
+		first := f.FirstChar()
+		if first <= code && code <= f.LastChar() && code-first < widths.Len() {
+			return widths.Index(code - first).Float64()
+		}
+	} else if m := f.standardFont(); m != nil && 0 <= code && code < 256 {
+		if w, ok := m.widths[f.simpleEncoding().names[code]]; ok {
+			return w
+		}
+	}
+	return f.V.Key("FontDescriptor").Key("MissingWidth").Float64()
+}
+
+// Font descriptor flags. See PDF 32000-1:2008, §9.8.2.
+const (
+	fontFixedPitch  = 1 << 0
+	fontSerif       = 1 << 1
+	fontSymbolic    = 1 << 2
+	fontScript      = 1 << 3
+	fontNonsymbolic = 1 << 5
+	fontItalic      = 1 << 6
+	fontAllCap      = 1 << 16
+	fontSmallCap    = 1 << 17
+	fontForceBold   = 1 << 18
+)
+
+// A stdFont holds the built-in metrics of one of the standard 14 fonts,
+// which PDF files may use without embedding or describing them.
+// All lengths are in glyph space units (1/1000 of the font size).
+type stdFont struct {
+	flags       int
+	ascent      float64
+	descent     float64
+	capHeight   float64
+	xHeight     float64
+	italicAngle float64
+	bbox        [4]float64
+	encoding    *[256]string       // built-in encoding
+	widths      map[string]float64 // glyph name -> advance width
+}
+
+// stdFontAliases maps other names commonly used for the standard 14 fonts,
+// such as those written by Windows applications, to their PostScript names.
+var stdFontAliases = map[string]string{
+	"Arial":                        "Helvetica",
+	"Arial,Bold":                   "Helvetica-Bold",
+	"Arial,BoldItalic":             "Helvetica-BoldOblique",
+	"Arial,Italic":                 "Helvetica-Oblique",
+	"Arial-Bold":                   "Helvetica-Bold",
+	"Arial-BoldItalic":             "Helvetica-BoldOblique",
+	"Arial-BoldItalicMT":           "Helvetica-BoldOblique",
+	"Arial-BoldMT":                 "Helvetica-Bold",
+	"Arial-Italic":                 "Helvetica-Oblique",
+	"Arial-ItalicMT":               "Helvetica-Oblique",
+	"ArialMT":                      "Helvetica",
+	"Courier,Bold":                 "Courier-Bold",
+	"Courier,BoldItalic":           "Courier-BoldOblique",
+	"Courier,Italic":               "Courier-Oblique",
+	"CourierNew":                   "Courier",
+	"CourierNew,Bold":              "Courier-Bold",
+	"CourierNew,BoldItalic":        "Courier-BoldOblique",
+	"CourierNew,Italic":            "Courier-Oblique",
+	"CourierNewPS-BoldItalicMT":    "Courier-BoldOblique",
+	"CourierNewPS-BoldMT":          "Courier-Bold",
+	"CourierNewPS-ItalicMT":        "Courier-Oblique",
+	"CourierNewPSMT":               "Courier",
+	"Helvetica,Bold":               "Helvetica-Bold",
+	"Helvetica,BoldItalic":         "Helvetica-BoldOblique",
+	"Helvetica,Italic":             "Helvetica-Oblique",
+	"Helvetica-BoldItalic":         "Helvetica-BoldOblique",
+	"Helvetica-Italic":             "Helvetica-Oblique",
+	"Symbol,Bold":                  "Symbol",
+	"Symbol,BoldItalic":            "Symbol",
+	"Symbol,Italic":                "Symbol",
+	"Times":                        "Times-Roman",
+	"Times,Bold":                   "Times-Bold",
+	"Times,BoldItalic":             "Times-BoldItalic",
+	"Times,Italic":                 "Times-Italic",
+	"TimesNewRoman":                "Times-Roman",
+	"TimesNewRoman,Bold":           "Times-Bold",
+	"TimesNewRoman,BoldItalic":     "Times-BoldItalic",
+	"TimesNewRoman,Italic":         "Times-Italic",
+	"TimesNewRomanPS-BoldItalicMT": "Times-BoldItalic",
+	"TimesNewRomanPS-BoldMT":       "Times-Bold",
+	"TimesNewRomanPS-ItalicMT":     "Times-Italic",
+	"TimesNewRomanPSMT":            "Times-Roman",
+}
+
+// standardFont returns the built-in metrics for f if its BaseFont names
+// one of the standard 14 fonts, or nil if it does not.
+func (f Font) standardFont() *stdFont {
+	if f.Subtype() == "Type0" || f.Subtype() == "Type3" {
+		return nil
+	}
+	name := f.BaseFont()
+	if i := strings.Index(name, "+"); i >= 0 {
+		name = name[i+1:]
+	}
+	if alias, ok := stdFontAliases[name]; ok {
+		name = alias
 	}
-	return f.V.Key("Widths").Index(code - first).Float64()
+	return stdFonts[name]
+}
+
+// codeWidth returns the width of the glyph selected by the character code raw.
+// For Type0 fonts m is the result of f.encodingCMap, passed in so that
+// callers showing many strings parse the CMap only once.
+func (f Font) codeWidth(m *cmap, raw string) float64 {
+	code := codeInt(raw)
+	if f.Subtype() != "Type0" {
+		return f.Width(code)
+	}
+	if m != nil {
+		code = m.cid(raw)
+	}
+	return f.cidWidth(code)
+}
+
+// extent returns the heights of the font's ascent line above the
+// baseline and of its descent line, negative if below, in text space
+// units (multiples of the font size). They are taken from the font
+// descriptor's Ascent and Descent, or else from its FontBBox, or else
+// from the metrics of a standard 14 font; without any of these, the
+// glyphs are assumed to span the font size, with a fifth below the baseline.
+func (f Font) extent() (ascent, descent float64) {
+	if f.cache != nil && f.cache.extent != nil {
+		return f.cache.extent[0], f.cache.extent[1]
+	}
+	ascent, descent = f.glyphExtent()
+	scale := f.fontMatrix()[1][1]
+	ascent, descent = ascent*scale, descent*scale
+	if f.cache != nil {
+		f.cache.extent = &[2]float64{ascent, descent}
+	}
+	return ascent, descent
+}
+
+// glyphExtent returns the ascent and descent of f in glyph space units.
+func (f Font) glyphExtent() (ascent, descent float64) {
+	d := f.V
+	if f.Subtype() == "Type0" {
+		d = f.descendant()
+	}
+	fd := d.Key("FontDescriptor")
+	if a, de := fd.Key("Ascent").Float64(), fd.Key("Descent").Float64(); a > de {
+		return a, de
+	}
+	bbox := fd.Key("FontBBox")
+	if f.Subtype() == "Type3" {
+		bbox = f.V.Key("FontBBox")
+	}
+	if bbox.Len() == 4 {
+		lly, ury := bbox.Index(1).Float64(), bbox.Index(3).Float64()
+		if ury < lly {
+			lly, ury = ury, lly
+		}
+		if ury > lly {
+			return ury, lly
+		}
+	}
+	if m := f.standardFont(); m != nil {
+		return m.ascent, m.descent
+	}
+	scale := f.fontMatrix()[1][1]
+	if scale == 0 {
+		return 0, 0
+	}
+	return ascender / scale, -descender / scale
+}
+
+// fontMatrix returns the matrix mapping glyph space to text space.
+// Type3 fonts give it in their FontMatrix entry; for all other fonts
+// glyph space units are 1/1000 of text space units.
+func (f Font) fontMatrix() matrix {
+	fm := f.V.Key("FontMatrix")
+	if f.Subtype() != "Type3" || fm.Len() != 6 {
+		return matrix{{0.001, 0, 0}, {0, 0.001, 0}, {0, 0, 1}}
+	}
+	var m matrix
+	for i := 0; i < 6; i++ {
+		m[i/2][i%2] = fm.Index(i).Float64()
+	}
+	m[2][2] = 1
+	return m
+}
+
+// glyphMatrix returns the font matrix fm scaled by 1000, which is the
+// identity for fonts other than Type3. Applied before the text rendering
+// matrix, it makes the font size and glyph origin taken from that matrix
+// account for a Type3 font's own glyph space.
+func glyphMatrix(fm matrix) matrix {
+	return matrix{
+		{1000 * fm[0][0], 1000 * fm[0][1], 0},
+		{1000 * fm[1][0], 1000 * fm[1][1], 0},
+		{fm[2][0], fm[2][1], 1},
+	}
+}
+
+// Resources returns the resource dictionary used by the glyph
+// procedures of a Type3 font. Glyph procedures of fonts without
+// one use the resources of the page or form that shows the text.
+func (f Font) Resources() Value {
+	return f.V.Key("Resources")
+}
+
+// CharProc returns the content stream describing the glyph selected by code
+// in a Type3 font, found in the CharProcs dictionary under the glyph name the
+// font's encoding gives the code. It returns a null Value for other fonts.
+func (f Font) CharProc(code int) Value {
+	if f.Subtype() != "Type3" || code < 0 || code > 255 {
+		return Value{}
+	}
+	return f.V.Key("CharProcs").Key(f.simpleEncoding().names[code])
+}
+
+// WalkCharProc interprets the glyph procedure selected by code in a Type3
+// font, calling do for each operator as Interpret does. Operands are in
+// glyph space, which the font's FontMatrix maps to text space.
+// It does nothing if f is not a Type3 font or has no glyph for code.
+func (f Font) WalkCharProc(code int, do func(stk *Stack, op string)) {
+	if strm := f.CharProc(code); strm.Kind() == Stream {
+		Interpret(strm, do)
+	}
+}
+
+// descendant returns the CIDFont dictionary of a Type0 font.
+func (f Font) descendant() Value {
+	return f.V.Key("DescendantFonts").Index(0)
+}
+
+// cidWidth returns the width of the glyph with the given CID in a Type0 font.
+// The W array holds entries of the form "c [w1 w2 ...]" and "cfirst clast w";
+// CIDs it does not mention get the default width DW.
+func (f Font) cidWidth(cid int) float64 {
+	d := f.descendant()
+	w := d.Key("W")
+	for i := 0; i+1 < w.Len(); {
+		first := int(w.Index(i).Float64())
+		next := w.Index(i + 1)
+		if next.Kind() == Array {
+			if first <= cid && cid < first+next.Len() {
+				return next.Index(cid - first).Float64()
+			}
+			i += 2
+			continue
+		}
+		if first <= cid && cid <= int(next.Float64()) {
+			return w.Index(i + 2).Float64()
+		}
+		i += 3
+	}
+	if dw := d.Key("DW"); dw.Kind() == Integer || dw.Kind() == Real {
+		return dw.Float64()
+	}
+	return 1000
+}
+
+// encodingCMap returns the CMap mapping character codes of a Type0 font to CIDs.
+// It returns nil for simple fonts and for the Identity-H and Identity-V encodings,
+// where every code is a two-byte CID.
+// Other predefined CMaps are not built into the package; for those the returned
+// CMap borrows the codespace of the ToUnicode CMap and maps codes to themselves.
+func (f Font) encodingCMap() *cmap {
+	if f.Subtype() != "Type0" {
+		return nil
+	}
+	enc := f.V.Key("Encoding")
+	if enc.Kind() == Stream {
+		return readCmap(enc)
+	}
+	switch enc.Name() {
+	case "Identity-H", "Identity-V":
+		return nil
+	}
+	if toUnicode := f.V.Key("ToUnicode"); toUnicode.Kind() == Stream {
+		if m := readCmap(toUnicode); m != nil {
+			return &cmap{space: m.space}
+		}
+	}
+	return nil
+}
+
+// codespace returns the codespace used to split strings shown with f into character codes,
+// given m, the result of f.encodingCMap.
+func (f Font) codespace(m *cmap) *codespace {
+	if m != nil && !m.space.empty() {
+		return &m.space
+	}
+	if f.Subtype() == "Type0" {
+		return &twoByteCodespace
+	}
+	return &oneByteCodespace
 }
 
 // Encoder returns the encoding between font code point sequences and UTF-8.
 func (f Font) Encoder() TextEncoding {
-	if f.enc == nil { // caching the Encoder so we don't have to continually parse charmap
-		f.enc = f.getEncoder()
+	if f.cache == nil {
+		return f.getEncoder()
+	}
+	if f.cache.enc == nil { // caching the Encoder so we don't have to continually parse charmap
+		f.cache.enc = f.getEncoder()
 	}
-	return f.enc
+	return f.cache.enc
 }
 
 func (f Font) getEncoder() TextEncoding {
 	enc := f.V.Key("Encoding")
 	switch enc.Kind() {
 	case Name:
+		if f.Subtype() == "Type0" { // a predefined CMap name
+			return f.charmapEncoding()
+		}
 		switch enc.Name() {
-		case "WinAnsiEncoding":
-			return &byteEncoder{&winAnsiEncoding}
-		case "MacRomanEncoding":
-			return &byteEncoder{&macRomanEncoding}
-		case "Identity-H":
+		case "WinAnsiEncoding", "MacRomanEncoding", "MacExpertEncoding", "StandardEncoding":
+			return f.simpleEncoding()
+		case "Identity-H", "Identity-V":
 			return f.charmapEncoding()
 		default:
 			println("unknown encoding", enc.Name())
 			return &nopEncoder{}
 		}
 	case Dict:
-		return &dictEncoder{enc.Key("Differences")}
+		return f.simpleEncoding()
 	case Null:
 		return f.charmapEncoding()
+	case Stream: // an embedded CMap
+		return f.charmapEncoding()
 	default:
 		println("unexpected encoding", enc.String())
 		return &nopEncoder{}
@@ -203,39 +577,15 @@ func (f *Font) charmapEncoding() TextEncoding {
 		}
 		return m
 	}
+	if f.Subtype() != "Type0" {
+		return f.simpleEncoding()
+	}
 
-	return &byteEncoder{&pdfDocEncoding}
-}
-
-type dictEncoder struct {
-	v Value
-}
-
-func (e *dictEncoder) Decode(raw string) (text string) {
-	r := make([]rune, 0, len(raw))
-	for i := 0; i < len(raw); i++ {
-		ch := rune(raw[i])
-		n := -1
-		for j := 0; j < e.v.Len(); j++ {
-			x := e.v.Index(j)
-			if x.Kind() == Integer {
-				n = int(x.Int64())
-				continue
-			}
-			if x.Kind() == Name {
-				if int(raw[i]) == n {
-					r := nameToRune[x.Name()]
-					if r != 0 {
-						ch = r
-						break
-					}
-				}
-				n++
-			}
-		}
-		r = append(r, ch)
+	var fallback TextEncoding = &byteEncoder{&pdfDocEncoding}
+	if enc := f.programEncoding(fallback); enc != nil {
+		return enc
 	}
-	return string(r)
+	return fallback
 }
 
 // A TextEncoding represents a mapping between
@@ -281,10 +631,100 @@ type bfrange struct {
 	dst Value
 }
 
+type cidrange struct {
+	lo  string
+	hi  string
+	cid int
+}
+
 type cmap struct {
-	space   [4][]byteRange // codespace range
-	bfrange []bfrange
-	bfchar  []bfchar
+	space    codespace // codespace range
+	bfrange  []bfrange
+	bfchar   []bfchar
+	cidrange []cidrange // cidrange and cidchar mappings; a cidchar is a range with lo == hi
+}
+
+// A codespace lists, for each code length of 1 to 4 bytes,
+// the byte ranges that form valid character codes of that length.
+type codespace [4][]byteRange
+
+var (
+	oneByteCodespace = codespace{0: {{"\x00", "\xff"}}}
+	twoByteCodespace = codespace{1: {{"\x00\x00", "\xff\xff"}}}
+)
+
+func (cs *codespace) empty() bool {
+	for _, r := range cs {
+		if len(r) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// next returns the length in bytes of the character code at the start of raw.
+// A byte that does not begin any valid code is treated as a one-byte code.
+func (cs *codespace) next(raw string) int {
+	for n := 1; n <= 4 && n <= len(raw); n++ {
+		for _, space := range cs[n-1] {
+			if space.low <= raw[:n] && raw[:n] <= space.high {
+				return n
+			}
+		}
+	}
+	return 1
+}
+
+// width returns the length in bytes of the shortest code length
+// in the codespace able to hold code.
+func (cs *codespace) width(code int) int {
+	for n := 1; n <= 4; n++ {
+		if len(cs[n-1]) > 0 && code < 1<<(8*uint(n)) {
+			return n
+		}
+	}
+	return 2
+}
+
+// split splits raw into its character codes.
+func (cs *codespace) split(raw string) []string {
+	var codes []string
+	for len(raw) > 0 {
+		n := cs.next(raw)
+		codes = append(codes, raw[:n])
+		raw = raw[n:]
+	}
+	return codes
+}
+
+// codeInt returns the big-endian integer value of the character code raw.
+func codeInt(raw string) int {
+	x := 0
+	for i := 0; i < len(raw); i++ {
+		x = x<<8 | int(raw[i])
+	}
+	return x
+}
+
+// codeString returns the n-byte big-endian encoding of code.
+func codeString(code, n int) string {
+	b := make([]byte, n)
+	for i := n - 1; i >= 0; i-- {
+		b[i] = byte(code)
+		code >>= 8
+	}
+	return string(b)
+}
+
+// cid returns the CID that the CMap assigns to the character code raw.
+// Codes without a cidrange or cidchar mapping map to themselves.
+func (m *cmap) cid(raw string) int {
+	for _, r := range m.cidrange {
+		if len(r.lo) == len(raw) && r.lo <= raw && raw <= r.hi {
+			return r.cid + codeInt(raw) - codeInt(r.lo)
+		}
+	}
+	return codeInt(raw)
 }
 
 func (m *cmap) Decode(raw string) (text string) {
@@ -396,6 +836,26 @@ func readCmap(toUnicode Value) *cmap {
 				dst, srcHi, srcLo := stk.Pop(), stk.Pop().RawString(), stk.Pop().RawString()
 				m.bfrange = append(m.bfrange, bfrange{srcLo, srcHi, dst})
 			}
+		case "begincidrange", "begincidchar":
+			n = int(stk.Pop().Int64())
+		case "endcidrange":
+			if n < 0 {
+				panic("missing begincidrange")
+			}
+			for i := 0; i < n; i++ {
+				cid, hi, lo := int(stk.Pop().Int64()), stk.Pop().RawString(), stk.Pop().RawString()
+				m.cidrange = append(m.cidrange, cidrange{lo, hi, cid})
+			}
+		case "endcidchar":
+			if n < 0 {
+				panic("missing begincidchar")
+			}
+			for i := 0; i < n; i++ {
+				cid, code := int(stk.Pop().Int64()), stk.Pop().RawString()
+				m.cidrange = append(m.cidrange, cidrange{code, code, cid})
+			}
+		case "usecmap":
+			stk.Pop() // name of the parent CMap; predefined CMaps are not built in
 		case "defineresource":
 			stk.Pop().Name() // category
 			value := stk.Pop()
@@ -427,14 +887,187 @@ func (x matrix) mul(y matrix) matrix {
 	return z
 }
 
+// apply returns the image of the point (x, y) under x.
+func (m matrix) apply(x, y float64) Point {
+	return Point{x*m[0][0] + y*m[1][0] + m[2][0], x*m[0][1] + y*m[1][1] + m[2][1]}
+}
+
+// rect returns the bounds of the image of r under m.
+func (m matrix) rect(r Rect) Rect {
+	out := Rect{m.apply(r.Min.X, r.Min.Y), m.apply(r.Min.X, r.Min.Y)}
+	for _, p := range []Point{m.apply(r.Max.X, r.Min.Y), m.apply(r.Max.X, r.Max.Y), m.apply(r.Min.X, r.Max.Y)} {
+		out = out.union(Rect{p, p})
+	}
+	return out
+}
+
 // A Text represents a single piece of text drawn on a page.
 type Text struct {
 	Font     string  // the font used
-	FontSize float64 // the font size, in points (1/72 of an inch)
+	FontSize float64 // the effective font size, in points (1/72 of an inch), after scaling by the text and transformation matrices
 	X        float64 // the X coordinate, in points, increasing left to right
 	Y        float64 // the Y coordinate, in points, increasing bottom to top
-	W        float64 // the width of the text, in points
+	W        float64 // the horizontal extent of the glyph's advance, in points
 	S        string  // the actual UTF-8 text
+
+	Marked *MarkedContent // the innermost marked-content sequence containing the text, or nil
+
+	// Angle is the direction of the baseline, in degrees
+	// counterclockwise from the X axis.
+	Angle float64
+
+	Mode      int        // the text rendering mode, such as RenderFill or RenderInvisible
+	Fill      color.RGBA // the colour filling the glyph
+	Stroke    color.RGBA // the colour stroking the glyph's outline
+	Invisible bool       // whether the glyph leaves no visible mark: it is not painted, is under half a point in size, or is painted only in white
+
+	// Quad is the glyph's box on the page: the corners at its origin
+	// and at the end of its advance on the descent line, followed by
+	// those at the end of its advance and at its origin on the ascent
+	// line. For rotated, skewed or mirrored text it need not be a
+	// rectangle aligned with the axes.
+	Quad [4]Point
+}
+
+// minVisibleSize is the font size, in points, below which text cannot be read.
+const minVisibleSize = 0.5
+
+// invisible reports whether a glyph of the given effective font size,
+// shown in rendering mode mode with the given colours, leaves no visible
+// mark: it is neither filled nor stroked, is too small to see, or is
+// painted only in white, which does not show on the usual white page.
+func invisible(mode int, size float64, fill, stroke color.RGBA) bool {
+	if mode == RenderInvisible || mode == RenderClip || size < minVisibleSize {
+		return true
+	}
+	fills := mode == RenderFill || mode == RenderFillStroke || mode == RenderFillClip || mode == RenderFillStrokeClip
+	strokes := mode == RenderStroke || mode == RenderFillStroke || mode == RenderStrokeClip || mode == RenderFillStrokeClip
+	return (!fills || fill == white) && (!strokes || stroke == white)
+}
+
+// Bounds returns the smallest rectangle containing t.Quad.
+func (t Text) Bounds() Rect {
+	r := Rect{t.Quad[0], t.Quad[0]}
+	for _, p := range t.Quad[1:] {
+		r = r.union(Rect{p, p})
+	}
+	return r
+}
+
+// A MarkedContent is a marked-content sequence in a content stream,
+// begun by BMC or BDC and ended by EMC. See PDF 32000-1:2008, §14.6.
+type MarkedContent struct {
+	Tag        string         // the sequence's tag, such as P, Span or Artifact
+	Properties Value          // the property list given to BDC, or a null Value
+	Parent     *MarkedContent // the enclosing sequence, or nil
+
+	hidden bool   // whether the sequence is in hidden optional content
+	stm    objptr // the form XObject whose content stream holds the sequence, or zero for the page's contents
+}
+
+// MCID returns the marked-content identifier that associates m with an element
+// of the document's structure tree: that of m or of the nearest enclosing
+// sequence that has one. It returns -1 if there is none.
+func (m *MarkedContent) MCID() int {
+	id, _ := m.mcid()
+	return id
+}
+
+// mcid returns the MCID of m, as MCID does, and the form XObject whose
+// content stream gives it, or zero for the page's contents. An MCID
+// identifies a sequence only within its content stream.
+func (m *MarkedContent) mcid() (int, objptr) {
+	for ; m != nil; m = m.Parent {
+		if id := m.Properties.Key("MCID"); id.Kind() == Integer {
+			return int(id.Int64()), m.stm
+		}
+	}
+	return -1, objptr{}
+}
+
+// ActualText returns the replacement text m gives for its content, if any.
+// Text extraction shows the replacement in place of the content's glyphs.
+func (m *MarkedContent) ActualText() (string, bool) {
+	if m == nil {
+		return "", false
+	}
+	v := m.Properties.Key("ActualText")
+	return v.Text(), v.Kind() == String
+}
+
+// Alt returns the alternate description of the content of m or of
+// the nearest enclosing sequence that has one, or "" if there is none.
+func (m *MarkedContent) Alt() string {
+	return m.inherited("Alt")
+}
+
+// Expansion returns the expansion of the abbreviation or acronym marked by m
+// or by the nearest enclosing sequence that has one (its E entry), or "".
+func (m *MarkedContent) Expansion() string {
+	return m.inherited("E")
+}
+
+func (m *MarkedContent) inherited(key string) string {
+	for ; m != nil; m = m.Parent {
+		if v := m.Properties.Key(key); v.Kind() == String {
+			return v.Text()
+		}
+	}
+	return ""
+}
+
+// beginMarked returns the sequence begun by a BMC or BDC operator with the
+// given operands, nested in parent. A property list given to BDC by name
+// is looked up in the Properties subdictionary of the resources res.
+func beginMarked(args []Value, res Value, stm objptr, parent *MarkedContent, oc *optionalContent) *MarkedContent {
+	m := &MarkedContent{Tag: args[0].Name(), Parent: parent, stm: stm}
+	if len(args) > 1 {
+		m.Properties = args[1]
+		if args[1].Kind() == Name {
+			m.Properties = res.Key("Properties").Key(args[1].Name())
+		}
+	}
+	oc.enter(m)
+	return m
+}
+
+// replacedBy returns the innermost of m and its enclosing sequences
+// that has ActualText, replacing the text shown inside it, or nil.
+func replacedBy(m *MarkedContent) *MarkedContent {
+	for ; m != nil; m = m.Parent {
+		if _, ok := m.ActualText(); ok {
+			return m
+		}
+	}
+	return nil
+}
+
+// replaceText replaces the glyphs in text by a single Text showing s,
+// covering the same span of the line, as directed by the ActualText
+// of the marked-content sequence m. If s is empty, the glyphs are
+// dropped; if there are no glyphs, there is nothing to replace.
+func replaceText(text []Text, s string, m *MarkedContent) []Text {
+	var first, last *Text
+	for i := range text {
+		if text[i].S != "\n" {
+			if first == nil {
+				first = &text[i]
+			}
+			last = &text[i]
+		}
+	}
+	if first == nil {
+		return text
+	}
+	if s == "" {
+		return nil
+	}
+	t := *first
+	t.W = last.X + last.W - first.X
+	t.S = s
+	t.Marked = m
+	t.Quad[1], t.Quad[2] = last.Quad[1], last.Quad[2]
+	return []Text{t}
 }
 
 type Image struct {
@@ -444,6 +1077,8 @@ type Image struct {
 	SoftMask         []byte
 	ColorSpace       string
 	Indexed          []uint8
+	Inline           bool // whether the image is inline in the content stream, between BI and EI, rather than an XObject
+	Rect             Rect // where the content stream first paints the image, in the coordinates of Content; zero if it does not
 }
 
 func (m Image) WritePng(writer io.Writer) error {
@@ -533,7 +1168,10 @@ type Point struct {
 // Content describes the basic content on a page: the text and any drawn rectangles.
 type Content struct {
 	Text []Text
-	Rect []Rect
+	Rect []Rect // the rectangles drawn with re, mapped by the transformation matrix into the space of Text; the bounds of any that are rotated or skewed
+
+	rules  []segment        // the straight lines stroked or filled, in page space
+	images []imagePlacement // the images painted, in order; each image XObject only where first painted
 }
 
 type gstate struct {
@@ -549,11 +1187,17 @@ type gstate struct {
 	Tlm   matrix
 	Trm   matrix
 	CTM   matrix
+
+	fillSpace, strokeSpace Value      // the current colour spaces
+	fill, stroke           color.RGBA // the current colours
+	clip                   *Rect      // the bounds of the clipping region set by form bounding boxes, or nil
 }
 
 // GetPlainText returns the page's all text without format.
+// Words are separated by spaces and lines by newlines, as described for Words.
 // fonts can be passed in (to improve parsing performance) or left nil
 func (p Page) GetPlainText(fonts map[string]*Font) (result string, err error) {
+	p = p.fixOptions()
 	defer func() {
 		if r := recover(); r != nil {
 			result = ""
@@ -561,74 +1205,7 @@ func (p Page) GetPlainText(fonts map[string]*Font) (result string, err error) {
 		}
 	}()
 
-	strm := p.V.Key("Contents")
-	var enc TextEncoding = &nopEncoder{}
-
-	if fonts == nil {
-		fonts = make(map[string]*Font)
-		for _, font := range p.Fonts() {
-			f := p.Font(font)
-			fonts[font] = &f
-		}
-	}
-
-	var textBuilder bytes.Buffer
-	showText := func(s string) {
-		for _, ch := range enc.Decode(s) {
-			_, err := textBuilder.WriteRune(ch)
-			if err != nil {
-				panic(err)
-			}
-		}
-	}
-
-	Interpret(strm, func(stk *Stack, op string) {
-		n := stk.Len()
-		args := make([]Value, n)
-		for i := n - 1; i >= 0; i-- {
-			args[i] = stk.Pop()
-		}
-
-		switch op {
-		default:
-			return
-		case "T*": // move to start of next line
-			showText("\n")
-		case "Tf": // set text font and size
-			if len(args) != 2 {
-				panic("bad TL")
-			}
-			if font, ok := fonts[args[0].Name()]; ok {
-				enc = font.Encoder()
-			} else {
-				enc = &nopEncoder{}
-			}
-		case "\"": // set spacing, move to next line, and show text
-			if len(args) != 3 {
-				panic("bad \" operator")
-			}
-			fallthrough
-		case "'": // move to next line and show text
-			if len(args) != 1 {
-				panic("bad ' operator")
-			}
-			fallthrough
-		case "Tj": // show text
-			if len(args) != 1 {
-				panic("bad Tj operator")
-			}
-			showText(args[0].RawString())
-		case "TJ": // show text, allowing individual glyph positioning
-			v := args[0]
-			for i := 0; i < v.Len(); i++ {
-				x := v.Index(i)
-				if x.Kind() == String {
-					showText(x.RawString())
-				}
-			}
-		}
-	})
-	return textBuilder.String(), nil
+	return plainText(p.extractOptions().finishWords(words(p.content(fonts).Text))), nil
 }
 
 // Column represents the contents of a column
@@ -642,6 +1219,7 @@ type Columns []*Column
 
 // GetTextByColumn returns the page's all text grouped by column
 func (p Page) GetTextByColumn() (Columns, error) {
+	p = p.fixOptions()
 	result := Columns{}
 	var err error
 
@@ -652,19 +1230,20 @@ func (p Page) GetTextByColumn() (Columns, error) {
 		}
 	}()
 
-	showText := func(enc TextEncoding, currentX, currentY float64, s string) {
+	showText := func(enc TextEncoding, currentX, currentY float64, s string, marked *MarkedContent) {
 		var textBuilder bytes.Buffer
 
-		for _, ch := range enc.Decode(s) {
+		for _, ch := range arabicBase(enc.Decode(s)) {
 			_, err := textBuilder.WriteRune(ch)
 			if err != nil {
 				panic(err)
 			}
 		}
 		text := Text{
-			S: textBuilder.String(),
-			X: currentX,
-			Y: currentY,
+			S:      textBuilder.String(),
+			X:      currentX,
+			Y:      currentY,
+			Marked: marked,
 		}
 
 		var currentColumn *Column
@@ -698,6 +1277,13 @@ func (p Page) GetTextByColumn() (Columns, error) {
 		return result[i].Position < result[j].Position
 	})
 
+	opts := p.extractOptions()
+	d := p.display()
+	for _, column := range result {
+		column.Content = TextVertical(opts.finishTexts(column.Content))
+		d.flipText(column.Content)
+	}
+
 	return result, err
 }
 
@@ -712,6 +1298,7 @@ type Rows []*Row
 
 // GetTextByRow returns the page's all text grouped by rows
 func (p Page) GetTextByRow() (result Rows, err error) {
+	p = p.fixOptions()
 	defer func() {
 		if r := recover(); r != nil {
 			result = Rows{}
@@ -719,9 +1306,9 @@ func (p Page) GetTextByRow() (result Rows, err error) {
 		}
 	}()
 
-	showText := func(enc TextEncoding, currentX, currentY float64, s string) {
+	showText := func(enc TextEncoding, currentX, currentY float64, s string, marked *MarkedContent) {
 		var textBuilder bytes.Buffer
-		for _, ch := range enc.Decode(s) {
+		for _, ch := range arabicBase(enc.Decode(s)) {
 			_, err := textBuilder.WriteRune(ch)
 			if err != nil {
 				panic(err)
@@ -731,9 +1318,10 @@ func (p Page) GetTextByRow() (result Rows, err error) {
 		//fmt.Println(textBuilder.String())
 
 		text := Text{
-			S: textBuilder.String(),
-			X: currentX,
-			Y: currentY,
+			S:      textBuilder.String(),
+			X:      currentX,
+			Y:      currentY,
+			Marked: marked,
 		}
 
 		for _, row := range result {
@@ -755,10 +1343,21 @@ func (p Page) GetTextByRow() (result Rows, err error) {
 		return result[i].Position > result[j].Position
 	})
 
+	opts := p.extractOptions()
+	if opts.Dehyphenate {
+		dehyphenateRows(result)
+	}
+	d := p.display()
+	for _, row := range result {
+		row.Content = TextHorizontal(opts.finishTexts(row.Content))
+		row.Position = d.flipY(row.Position)
+		d.flipText(row.Content)
+	}
+
 	return result, err
 }
 
-func (p Page) walkTextBlocks(walker func(enc TextEncoding, x, y float64, s string)) {
+func (p Page) walkTextBlocks(walker func(enc TextEncoding, x, y float64, s string, marked *MarkedContent)) {
 	strm := p.V.Key("Contents")
 
 	fonts := make(map[string]*Font)
@@ -769,6 +1368,31 @@ func (p Page) walkTextBlocks(walker func(enc TextEncoding, x, y float64, s strin
 
 	var enc TextEncoding = &nopEncoder{}
 	var currentX, currentY float64
+	var marked *MarkedContent
+	pending := make(map[*MarkedContent][2]float64) // where each open sequence with ActualText first showed text
+	oc := p.V.r.optionalContent(p.extractOptions())
+	d := p.display()
+	res := p.Resources()
+	base := ident // maps the space of the page or form being interpreted to the space of the results
+	if d != nil {
+		base = d.m
+	}
+	var clip *Rect // the clipping bounds of the forms being interpreted
+	var stm objptr // the form being interpreted, or zero for the page's contents
+	forms := make(map[objptr]bool)
+	var paintForm func(form Value, m matrix) // interprets a form, mapped to the enclosing space by m
+	show := func(enc TextEncoding, s string) {
+		if marked.isHidden() || clip != nil && !contains(*clip, Point{currentX, currentY}) {
+			return
+		}
+		if m := replacedBy(marked); m != nil {
+			if _, ok := pending[m]; !ok {
+				pending[m] = [2]float64{currentX, currentY}
+			}
+			return
+		}
+		walker(enc, currentX, currentY, s, marked)
+	}
 	walkerFunc := func(stk *Stack, op string) {
 		n := stk.Len()
 		args := make([]Value, n)
@@ -810,7 +1434,7 @@ func (p Page) walkTextBlocks(walker func(enc TextEncoding, x, y float64, s strin
 				//panic("bad Tj operator")
 			}
 
-			walker(enc, currentX, currentY, args[0].RawString())
+			show(enc, args[0].RawString())
 		case "TJ": // show text, allowing individual glyph positioning
 			if len(args) < 1 {
 				return
@@ -820,20 +1444,64 @@ func (p Page) walkTextBlocks(walker func(enc TextEncoding, x, y float64, s strin
 			for i := 0; i < v.Len(); i++ {
 				x := v.Index(i)
 				if x.Kind() == String {
-					switch sv := x.data.(type) {
-					case string:
-						walker(enc, currentX, currentY, sv)
-					case rawString:
-						walker(&nopEncoder{}, currentX, currentY, string(sv))
-					}
+					show(enc, x.RawString())
 				}
 			}
 		case "Td":
-			walker(enc, currentX, currentY, "")
+			if !marked.isHidden() {
+				walker(enc, currentX, currentY, "", marked)
+			}
 		case "Tm":
-			currentX = args[4].Float64()
-			currentY = args[5].Float64()
+			pt := base.apply(args[4].Float64(), args[5].Float64())
+			currentX, currentY = pt.X, pt.Y
+		case "BMC", "BDC": // begin marked-content sequence
+			if op == "BMC" && len(args) != 1 || op == "BDC" && len(args) != 2 {
+				return
+			}
+			marked = beginMarked(args, res, stm, marked, oc)
+		case "EMC": // end marked-content sequence
+			if marked == nil {
+				return
+			}
+			if pos, ok := pending[marked]; ok {
+				delete(pending, marked)
+				s, _ := marked.ActualText()
+				if m := replacedBy(marked.Parent); m != nil {
+					if _, ok := pending[m]; !ok {
+						pending[m] = pos
+					}
+				} else if s != "" {
+					walker(&nopEncoder{}, pos[0], pos[1], s, marked)
+				}
+			}
+			marked = marked.Parent
+		case "Do": // paint XObject
+			if len(args) != 1 {
+				return
+			}
+			form := res.Key("XObject").Key(args[0].Name())
+			if !isForm(form) || forms[form.ptr] || len(forms) >= maxFormDepth || marked.isHidden() || !oc.visible(form.Key("OC")) {
+				return
+			}
+			paintForm(form, formMatrix(form))
+		}
+	}
+	paintForm = func(form Value, m matrix) {
+		savedBase, savedClip, savedRes, savedFonts, savedMarked, savedStm := base, clip, res, fonts, marked, stm
+		base = m.mul(base)
+		clip = formClip(form, base, clip)
+		if r := form.Key("Resources"); r.Kind() == Dict {
+			res = r
+			fonts = make(map[string]*Font)
+			for _, name := range r.Key("Font").Keys() {
+				fonts[name] = &Font{r.Key("Font").Key(name), new(fontCache)}
+			}
 		}
+		forms[form.ptr] = true
+		stm = form.ptr
+		Interpret(form, walkerFunc)
+		delete(forms, form.ptr)
+		base, clip, res, fonts, marked, stm = savedBase, savedClip, savedRes, savedFonts, savedMarked, savedStm
 	}
 	switch v := strm.data.(type) {
 	case stream:
@@ -844,46 +1512,140 @@ func (p Page) walkTextBlocks(walker func(enc TextEncoding, x, y float64, s strin
 			Interpret(sub, walkerFunc)
 		}
 	}
+	for _, a := range p.appearances(oc) {
+		marked = nil
+		paintForm(a.form, a.m)
+	}
 }
 
 // Content returns the page's content.
+// The text is normalized, and the coordinates given in display space,
+// as set by the Reader's SetExtractOptions.
 func (p Page) Content() Content {
+	p = p.fixOptions()
+	c := p.content(nil)
+	c.Text = p.extractOptions().normalizeText(c.Text)
+	if d := p.display(); d != nil {
+		d.flipText(c.Text)
+		for i, r := range c.Rect {
+			c.Rect[i] = d.flipRect(r)
+		}
+	}
+	return c
+}
+
+// content interprets the page's content stream. Fonts are looked up
+// in fonts by resource name, and added to it as they are first used.
+func (p Page) content(fonts map[string]*Font) Content {
+	return p.walkContent(fonts, nil)
+}
+
+// walkContent is the implementation of content. If glyph is not nil,
+// walkContent passes each glyph shown to glyph, including those without
+// text, rather than collecting them in the Content's Text.
+func (p Page) walkContent(fonts map[string]*Font, glyph func(Glyph)) Content {
+	if fonts == nil {
+		fonts = make(map[string]*Font)
+	}
 	strm := p.V.Key("Contents")
 	var enc TextEncoding = &nopEncoder{}
 
+	d := p.display()
+	gray := Value{data: name("DeviceGray")}
 	var g = gstate{
-		Th:  1,
-		CTM: ident,
+		Th:          1,
+		CTM:         ident,
+		fillSpace:   gray,
+		strokeSpace: gray,
+		fill:        black,
+		stroke:      black,
+	}
+	if d != nil {
+		g.CTM = d.m
 	}
 
-	var text []Text
+	var (
+		text     []Text
+		cids     *cmap                                       // maps codes to CIDs for the current Type0 font
+		space    = &oneByteCodespace                         // splits shown strings into character codes
+		marked   *MarkedContent                              // the innermost open marked-content sequence
+		replaced = make(map[*MarkedContent]int)              // where the text of each open sequence with ActualText begins
+		oc       = p.V.r.optionalContent(p.extractOptions()) // the visibility of optional content
+	)
 	showText := func(enc TextEncoding, s string) {
-		n := 0
-		decoded := enc.Decode(s)
-		for _, ch := range decoded {
-			var w0 float64
-			if n < len(s) {
-				w0 = g.Tf.Width(int(s[n]))
-			}
-			n++
+		f := g.Tf.BaseFont()
+		if i := strings.Index(f, "+"); i >= 0 {
+			f = f[i+1:]
+		}
 
-			f := g.Tf.BaseFont()
-			if i := strings.Index(f, "+"); i >= 0 {
-				f = f[i+1:]
-			}
+		fm := g.Tf.fontMatrix()
+		ascent, descent := g.Tf.extent()
+		for _, code := range space.split(s) {
+			w0 := g.Tf.codeWidth(cids, code) * fm[0][0]
 
 			Trm := matrix{{g.Tfs * g.Th, 0, 0}, {0, g.Tfs, 0}, {0, g.Trise, 1}}.mul(g.Tm).mul(g.CTM)
-			text = append(text, Text{f, Trm[0][0], Trm[2][0], Trm[2][1], w0 / 1000 * Trm[0][0], string(ch)})
+			Grm := glyphMatrix(fm).mul(Trm)
+			raw := enc.Decode(code)
+			decoded := arabicBase(raw)
+			origin := Point{Grm[2][0], Grm[2][1]}
+			if (decoded != "" || glyph != nil) && !marked.isHidden() && (g.clip == nil || contains(*g.clip, origin)) {
+				size := math.Hypot(Grm[1][0], Grm[1][1])
+				t := Text{
+					Font:      f,
+					FontSize:  size,
+					X:         Grm[2][0],
+					Y:         Grm[2][1],
+					W:         w0 * Trm[0][0],
+					S:         decoded,
+					Marked:    marked,
+					Mode:      g.Tmode,
+					Fill:      g.fill,
+					Stroke:    g.stroke,
+					Invisible: invisible(g.Tmode, size, g.fill, g.stroke),
+					Angle:     math.Atan2(Trm[0][1], Trm[0][0]) * 180 / math.Pi,
+					Quad: [4]Point{
+						Trm.apply(0, descent),
+						Trm.apply(w0, descent),
+						Trm.apply(w0, ascent),
+						Trm.apply(0, ascent),
+					},
+				}
+				if glyph == nil {
+					text = append(text, t)
+				} else if d == nil || contains(d.box, origin) {
+					glyph(g.Tf.glyph(t, cids, code, raw))
+				}
+			}
 
-			tx := w0/1000*g.Tfs + g.Tc
+			tx := w0*g.Tfs + g.Tc
+			if code == " " {
+				tx += g.Tw // word spacing applies to single-byte code 32 only
+			}
 			tx *= g.Th
 			g.Tm = matrix{{1, 0, 0}, {0, 1, 0}, {tx, 0, 1}}.mul(g.Tm)
 		}
 	}
+	endLine := func() {
+		if glyph != nil {
+			return
+		}
+		Trm := glyphMatrix(g.Tf.fontMatrix()).mul(matrix{{g.Tfs * g.Th, 0, 0}, {0, g.Tfs, 0}, {0, g.Trise, 1}}).mul(g.Tm).mul(g.CTM)
+		text = append(text, Text{S: "\n", FontSize: Trm[0][0], X: Trm[2][0], Y: Trm[2][1], Marked: marked})
+	}
 
 	var (
 		rect            []Rect
+		rules           []segment // painted straight lines
+		path            []segment // the straight lines of the current path
+		start, cur      Point     // the start of the current subpath and the current point
 		gstack          []gstate
+		res             = p.Resources()                     // the resources of the page or form being interpreted
+		stm             objptr                              // the form being interpreted, or zero for the page's contents
+		forms           = make(map[objptr]bool)             // the forms being interpreted, to stop cycles
+		formFonts       = make(map[objptr]map[string]*Font) // the fonts of forms with their own resources
+		paintForm       func(form Value, m matrix)          // interprets a form, mapped to the enclosing space by m
+		images          []imagePlacement
+		placed          = make(map[objptr]bool) // the image XObjects painted
 		interpretDoFunc = func(stk *Stack, op string) {
 			n := stk.Len()
 			args := make([]Value, n)
@@ -913,20 +1675,126 @@ func (p Page) Content() Content {
 				//fmt.Println("FONT", font)
 				//}
 
-			case "f": // fill
-			case "g": // setgray
-			case "l": // lineto
 			case "m": // moveto
+				if len(args) != 2 {
+					return
+				}
+				start = g.CTM.apply(args[0].Float64(), args[1].Float64())
+				cur = start
+
+			case "l": // lineto
+				if len(args) != 2 {
+					return
+				}
+				next := g.CTM.apply(args[0].Float64(), args[1].Float64())
+				path = append(path, segment{cur, next})
+				cur = next
+
+			case "c", "v", "y": // curveto
+				if len(args) >= 4 {
+					cur = g.CTM.apply(args[len(args)-2].Float64(), args[len(args)-1].Float64())
+				}
+
+			case "h": // closepath
+				path = append(path, segment{cur, start})
+				cur = start
 
-			case "cs": // set colorspace non-stroking
-			case "scn": // set color non-stroking
+			case "S", "s", "f", "F", "f*", "B", "B*", "b", "b*": // paint path
+				if (op == "s" || op == "b" || op == "b*") && cur != start {
+					path = append(path, segment{cur, start})
+				}
+				if !marked.isHidden() {
+					rules = append(rules, path...)
+				}
+				path = nil
+
+			case "n": // end path without painting
+				path = nil
+
+			case "g", "G", "rg", "RG", "k", "K": // set device colour space and colour
+				space := map[string]string{"g": "DeviceGray", "rg": "DeviceRGB", "k": "DeviceCMYK"}[strings.ToLower(op)]
+				cs := Value{data: name(space)}
+				if len(args) != colorComponents(cs) {
+					return
+				}
+				c := toRGB(cs, floats(args))
+				if op == strings.ToLower(op) {
+					g.fillSpace, g.fill = cs, c
+				} else {
+					g.strokeSpace, g.stroke = cs, c
+				}
+
+			case "cs", "CS": // set colour space
+				if len(args) != 1 {
+					return
+				}
+				cs := colorSpace(args[0], res)
+				if op == "cs" {
+					g.fillSpace, g.fill = cs, initialColor(cs)
+				} else {
+					g.strokeSpace, g.stroke = cs, initialColor(cs)
+				}
+
+			case "sc", "scn", "SC", "SCN": // set colour
+				cs := g.fillSpace
+				if op == "SC" || op == "SCN" {
+					cs = g.strokeSpace
+				}
+				c := black // patterns are taken to be black
+				if colorFamily(cs) != "Pattern" {
+					c = toRGB(cs, floats(args))
+				}
+				if op == "sc" || op == "scn" {
+					g.fill = c
+				} else {
+					g.stroke = c
+				}
+
+			case "BMC", "BDC": // begin marked-content sequence
+				if op == "BMC" && len(args) != 1 || op == "BDC" && len(args) != 2 {
+					return
+				}
+				marked = beginMarked(args, res, stm, marked, oc)
+				if _, ok := marked.ActualText(); ok {
+					replaced[marked] = len(text)
+				}
+
+			case "EMC": // end marked-content sequence
+				if marked == nil {
+					return
+				}
+				if s, ok := marked.ActualText(); ok {
+					start := replaced[marked]
+					text = append(text[:start], replaceText(text[start:], s, marked)...)
+					delete(replaced, marked)
+				}
+				marked = marked.Parent
 
 			case "re": // append rectangle to path
 				if len(args) != 4 {
 					panic("bad re")
 				}
 				x, y, w, h := args[0].Float64(), args[1].Float64(), args[2].Float64(), args[3].Float64()
-				rect = append(rect, Rect{Point{x, y}, Point{x + w, y + h}})
+				corners := [4]Point{
+					g.CTM.apply(x, y),
+					g.CTM.apply(x+w, y),
+					g.CTM.apply(x+w, y+h),
+					g.CTM.apply(x, y+h),
+				}
+				for i := range corners {
+					path = append(path, segment{corners[i], corners[(i+1)%4]})
+				}
+				start, cur = corners[0], corners[0]
+				if marked.isHidden() {
+					return
+				}
+				r := g.CTM.rect(Rect{Point{x, y}, Point{x + w, y + h}})
+				if g.clip != nil {
+					if r = intersect(r, *g.clip); r.Min.X > r.Max.X || r.Min.Y > r.Max.Y {
+						return
+					}
+				}
+				rect = append(rect, r)
 
 			case "q": // save graphics state
 				gstack = append(gstack, g)
@@ -979,12 +1847,17 @@ func (p Page) Content() Content {
 					//panic("bad TL")
 				}
 				f := args[0].Name()
-				g.Tf = p.Font(f)
+				if _, ok := fonts[f]; !ok {
+					fonts[f] = &Font{res.Key("Font").Key(f), new(fontCache)}
+				}
+				g.Tf = *fonts[f]
 				enc = g.Tf.Encoder()
 				if enc == nil {
 					println("no cmap for", f)
 					enc = &nopEncoder{}
 				}
+				cids = g.Tf.encodingCMap()
+				space = g.Tf.codespace(cids)
 				g.Tfs = args[1].Float64()
 
 			case "\"": // set spacing, move to next line, and show text
@@ -1018,19 +1891,13 @@ func (p Page) Content() Content {
 				for i := 0; i < v.Len(); i++ {
 					x := v.Index(i)
 					if x.Kind() == String {
-						switch sv := x.data.(type) {
-						case string:
-							showText(enc, sv)
-						case rawString:
-							showText(&nopEncoder{}, string(sv))
-						}
-
+						showText(enc, x.RawString())
 					} else {
 						tx := -x.Float64() / 1000 * g.Tfs * g.Th
 						g.Tm = matrix{{1, 0, 0}, {0, 1, 0}, {tx, 0, 1}}.mul(g.Tm)
 					}
 				}
-				showText(&nopEncoder{}, "\n")
+				endLine()
 
 			case "TL": // set text leading
 				if len(args) != 1 {
@@ -1075,23 +1942,95 @@ func (p Page) Content() Content {
 					panic("bad Tz")
 				}
 				g.Th = args[0].Float64() / 100
+
+			case "BI": // inline image
+				if len(args) != 2 || marked.isHidden() {
+					return
+				}
+				hdr, _ := args[0].data.(dict)
+				data, _ := args[1].data.(string)
+				images = append(images, imagePlacement{hdr: hdr, data: []byte(data), res: res, rect: g.CTM.rect(unitSquare)})
+
+			case "Do": // paint XObject
+				if len(args) != 1 {
+					return
+				}
+				form := res.Key("XObject").Key(args[0].Name())
+				if form.Key("Subtype").Name() == "Image" {
+					if !placed[form.ptr] && !marked.isHidden() && oc.visible(form.Key("OC")) {
+						placed[form.ptr] = true
+						images = append(images, imagePlacement{xobj: form.ptr, rect: g.CTM.rect(unitSquare)})
+					}
+					return
+				}
+				if !isForm(form) || forms[form.ptr] || len(forms) >= maxFormDepth || marked.isHidden() || !oc.visible(form.Key("OC")) {
+					return
+				}
+				paintForm(form, formMatrix(form))
 			}
 		}
 	)
+	paintForm = func(form Value, m matrix) {
+		savedG, savedStack, savedRes, savedFonts, savedMarked, savedStm := g, gstack, res, fonts, marked, stm
+		g.CTM = m.mul(g.CTM)
+		g.clip = formClip(form, g.CTM, g.clip)
+		gstack = nil
+		if r := form.Key("Resources"); r.Kind() == Dict {
+			res = r
+			if formFonts[form.ptr] == nil {
+				formFonts[form.ptr] = make(map[string]*Font)
+			}
+			fonts = formFonts[form.ptr]
+		}
+		forms[form.ptr] = true
+		stm = form.ptr
+		Interpret(form, interpretDoFunc)
+		delete(forms, form.ptr)
+		g, gstack, res, fonts, marked, stm = savedG, savedStack, savedRes, savedFonts, savedMarked, savedStm
+	}
+	initial := g
 	Interpret(strm, interpretDoFunc)
-	return Content{text, rect}
+	for _, a := range p.appearances(oc) {
+		g, gstack, marked = initial, nil, nil
+		paintForm(a.form, a.m)
+	}
+	c := Content{Text: text, Rect: rect, rules: rules, images: images}
+	if d != nil {
+		d.clip(&c)
+	}
+	return c
+}
+
+// floats returns the numeric values in args.
+func floats(args []Value) []float64 {
+	var out []float64
+	for _, a := range args {
+		if a.Kind() == Integer || a.Kind() == Real {
+			out = append(out, a.Float64())
+		}
+	}
+	return out
 }
 
+// Images returns the page's images: the images its content stream paints,
+// including inline images and images painted by forms, in the order it
+// first paints them, then the other image XObjects in its resources,
+// in the order of their names.
 func (p Page) Images() []Image {
-	value := p.Resources().Key("XObject")
-	if value.IsNull() {
-		return []Image{}
-	}
-	dicts := value.data.(dict)
-	var images []Image
-	for k, v := range dicts {
-		if strings.HasPrefix(string(k), "Image") {
-			result := p.V.r.resolve(p.V.ptr, v)
+	p = p.fixOptions()
+	placements, _ := imagePlacements(p)
+	d := p.display()
+	dicts, _ := p.Resources().Key("XObject").data.(dict)
+	var names []string
+	for k := range dicts {
+		names = append(names, string(k))
+	}
+	sort.Strings(names)
+	listed := make(map[objptr]Image) // the image XObjects in the resources, by object
+	var unplaced []objptr
+	for _, k := range names {
+		if strings.HasPrefix(k, "Image") {
+			result := p.V.r.resolve(p.V.ptr, dicts[name(k)])
 			reader := result.Reader()
 			b, e := ioutil.ReadAll(reader)
 			if e != nil {
@@ -1131,12 +2070,52 @@ func (p Page) Images() []Image {
 					panic(e)
 				}
 			}
+			if _, ok := listed[result.ptr]; !ok {
+				listed[result.ptr] = img
+				unplaced = append(unplaced, result.ptr)
+			}
+		}
+	}
+
+	images := []Image{}
+	for _, in := range placements {
+		if in.hdr == nil {
+			img, ok := listed[in.xobj]
+			if !ok {
+				continue // not in the page's resources
+			}
+			delete(listed, in.xobj)
+			img.Rect = d.flipRect(in.rect)
+			images = append(images, img)
+			continue
+		}
+		img, err := inlineImage(in.hdr, in.data, in.res)
+		if err != nil {
+			continue // cannot decode the data
+		}
+		img.Rect = d.flipRect(in.rect)
+		images = append(images, img)
+	}
+	for _, ptr := range unplaced {
+		if img, ok := listed[ptr]; ok {
 			images = append(images, img)
 		}
 	}
 	return images
 }
 
+// imagePlacements returns the images painted by p's content stream,
+// recovering from the panics of a malformed content stream.
+func imagePlacements(p Page) (images []imagePlacement, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			images = nil
+			err = errors.New(fmt.Sprint(r))
+		}
+	}()
+	return p.content(nil).images, nil
+}
+
 // TextVertical implements sort.Interface for sorting
 // a slice of Text values in vertical order, top to bottom,
 // and then left to right within a line.
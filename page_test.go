@@ -10,6 +10,7 @@ import (
 	"image"
 	"image/color"
 	"io/ioutil"
+	"math"
 	"os"
 	"sort"
 	"strconv"
@@ -490,3 +491,327 @@ func drawRect(rows []RectSlice) {
 	draw2dimg.SaveToPngFile(fmt.Sprintf("rect_%d.png", idx), img)
 	idx += 1
 }
+
+func TestCIDFontWidth(t *testing.T) {
+	font := Font{V: Value{data: dict{
+		"Subtype":  name("Type0"),
+		"Encoding": name("Identity-H"),
+		"DescendantFonts": array{dict{
+			"Subtype": name("CIDFontType2"),
+			"DW":      int64(500),
+			"W":       array{int64(1), array{int64(700), int64(600)}, int64(10), int64(20), int64(300)},
+		}},
+	}}}
+	for _, tt := range []struct {
+		code  int
+		width float64
+	}{
+		{0, 500}, {1, 700}, {2, 600}, {3, 500}, {10, 300}, {20, 300}, {21, 500},
+	} {
+		assert.Equal(t, tt.width, font.Width(tt.code))
+	}
+
+	codes := font.codespace(font.encodingCMap()).split("\x00\x01\x00\x0a\x00")
+	assert.Equal(t, []string{"\x00\x01", "\x00\x0a", "\x00"}, codes)
+}
+
+func TestStandardFontWidth(t *testing.T) {
+	helvetica := Font{V: Value{data: dict{
+		"Subtype":  name("Type1"),
+		"BaseFont": name("Helvetica"),
+		"Encoding": dict{
+			"BaseEncoding": name("WinAnsiEncoding"),
+			"Differences":  array{int64(65), name("W")},
+		},
+	}}}
+	assert.Equal(t, 944.0, helvetica.Width('A'))
+	assert.Equal(t, 667.0, helvetica.Width('B'))
+	assert.Equal(t, 556.0, helvetica.Width(0x80)) // Euro
+
+	times := Font{V: Value{data: dict{"Subtype": name("TrueType"), "BaseFont": name("ABCDEF+TimesNewRomanPS-BoldMT")}}}
+	assert.Equal(t, 722.0, times.Width('A'))
+
+	missing := Font{V: Value{data: dict{
+		"Subtype":        name("TrueType"),
+		"BaseFont":       name("Unknown"),
+		"FirstChar":      int64(32),
+		"LastChar":       int64(32),
+		"Widths":         array{int64(250)},
+		"FontDescriptor": dict{"MissingWidth": int64(400)},
+	}}}
+	assert.Equal(t, 250.0, missing.Width(' '))
+	assert.Equal(t, 400.0, missing.Width('A'))
+}
+
+func TestType3Font(t *testing.T) {
+	font := Font{V: Value{data: dict{
+		"Subtype":    name("Type3"),
+		"FontMatrix": array{0.01, int64(0), int64(0), 0.01, int64(0), int64(0)},
+		"FirstChar":  int64(65),
+		"LastChar":   int64(66),
+		"Widths":     array{int64(50), int64(60)},
+		"Encoding":   dict{"Differences": array{int64(65), name("a65"), name("B")}},
+		"CharProcs":  dict{},
+	}}}
+	fm := font.fontMatrix()
+	assert.Equal(t, 0.5, font.Width('A')*fm[0][0])
+	assert.Equal(t, matrix{{10, 0, 0}, {0, 10, 0}, {0, 0, 1}}, glyphMatrix(fm))
+	assert.Equal(t, ident, glyphMatrix(Font{}.fontMatrix()))
+	assert.Equal(t, "AB", font.Encoder().Decode("AB"))
+	assert.Equal(t, Null, font.CharProc('A').Kind())
+}
+
+// newTestPDF returns a Reader for a PDF file made of the given objects,
+// numbered from 1. Object 1 must be the document catalog. Objects that
+// begin with "stream\n" are written as streams, with the rest of the
+// object as their data and a Length entry added to dict, which precedes
+// "stream\n" and may be empty.
+func newTestPDF(t *testing.T, objs ...string) *Reader {
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.7\n")
+	offsets := make([]int, len(objs))
+	for i, obj := range objs {
+		offsets[i] = buf.Len()
+		if j := strings.Index(obj, "stream\n"); j >= 0 && !strings.Contains(obj[:j], "endstream") {
+			dict, data := strings.TrimSpace(obj[:j]), obj[j+len("stream\n"):]
+			if dict == "" {
+				dict = "<<>>"
+			}
+			dict = strings.TrimSuffix(dict, ">>") + fmt.Sprintf(" /Length %d>>", len(data))
+			obj = dict + "\nstream\n" + data + "\nendstream"
+		}
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objs)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objs)+1, xref)
+	r, err := NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestMarkedContent(t *testing.T) {
+	r := newTestPDF(t,
+		`<< /Type /Catalog /Pages 2 0 R >>`,
+		`<< /Type /Pages /Kids [3 0 R] /Count 1 >>`,
+		`<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 4 0 R
+		   /Resources << /Font << /F1 << /Type /Font /Subtype /Type1 /BaseFont /Helvetica >> >>
+		                 /Properties << /P1 << /E (Doctor) >> >> >> >>`,
+		`stream
+BT /F1 12 Tf 72 720 Td
+/Span /P1 BDC (Dr.) Tj EMC
+( ) Tj
+/Span << /ActualText <FEFF00660069> >> BDC (\001) Tj EMC
+(nd) Tj
+0 -20 Td /Span << /ActualText () >> BDC (gone) Tj EMC
+/Figure << /Alt (A line) >> BDC /Span << /ActualText (x) >> BDC (y) Tj EMC EMC
+ET`,
+	)
+	p := r.Page(1)
+	words := p.Words()
+	var got []string
+	for _, w := range words {
+		got = append(got, w.S)
+	}
+	assert.Equal(t, []string{"Dr.", "find", "x"}, got)
+	assert.Equal(t, "Doctor", words[0].Marked.Expansion())
+	assert.Equal(t, "A line", words[2].Marked.Alt())
+
+	text, err := p.GetPlainText(nil)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "Dr. find\nx\n", text)
+
+	rows, err := p.GetTextByRow()
+	assert.Equal(t, nil, err)
+	var shown []string
+	for _, row := range rows {
+		for _, t := range row.Content {
+			shown = append(shown, t.S)
+		}
+	}
+	assert.Equal(t, []string{"", "Dr.", " ", "fi", "nd", "", "x"}, shown) // Td shows "" at the old position
+}
+
+func TestGlyphGeometry(t *testing.T) {
+	r := newTestPDF(t,
+		`<< /Type /Catalog /Pages 2 0 R >>`,
+		`<< /Type /Pages /Kids [3 0 R] /Count 1 >>`,
+		`<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 4 0 R
+		   /Resources << /Font << /F1 << /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>
+		                          /F2 << /Type /Font /Subtype /TrueType /BaseFont /Other /FirstChar 73 /LastChar 73 /Widths [500]
+		                                 /FontDescriptor << /Ascent 900 /Descent -100 >> >> >> >> >>`,
+		`stream
+BT /F1 10 Tf 0 1 -1 0 300 400 Tm (I) Tj
+/F2 10 Tf 2 0 0 1 100 100 Tm (I) Tj ET`,
+	)
+	text := r.Page(1).Content().Text
+	round := func(p Point) Point {
+		return Point{math.Round(p.X*100) / 100, math.Round(p.Y*100) / 100}
+	}
+
+	// Helvetica rotated a quarter turn: its ascent is 718 and descent -207.
+	rotated := text[0]
+	assert.Equal(t, 10.0, rotated.FontSize)
+	assert.Equal(t, 90.0, rotated.Angle)
+	var quad [4]Point
+	for i, p := range rotated.Quad {
+		quad[i] = round(p)
+	}
+	assert.Equal(t, [4]Point{{302.07, 400}, {302.07, 402.78}, {292.82, 402.78}, {292.82, 400}}, quad)
+	assert.Equal(t, Rect{Point{292.82, 400}, Point{302.07, 402.78}}, Rect{round(rotated.Bounds().Min), round(rotated.Bounds().Max)})
+
+	// Stretched horizontally, with the extent from the font descriptor.
+	wide := text[1]
+	assert.Equal(t, 10.0, wide.FontSize)
+	assert.Equal(t, 0.0, wide.Angle)
+	assert.Equal(t, [4]Point{{100, 99}, {110, 99}, {110, 109}, {100, 109}}, wide.Quad)
+}
+
+func TestTextAppearance(t *testing.T) {
+	r := newTestPDF(t,
+		`<< /Type /Catalog /Pages 2 0 R >>`,
+		`<< /Type /Pages /Kids [3 0 R] /Count 1 >>`,
+		`<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 4 0 R
+		   /Resources << /Font << /F1 << /Type /Font /Subtype /Type1 /BaseFont /Helvetica >> >>
+		                 /ColorSpace << /CS0 [/Separation /Spot /DeviceCMYK
+		                                      << /FunctionType 2 /Domain [0 1] /C0 [0 0 0 0] /C1 [0 1 0 0] /N 1 >>] >> >> >>`,
+		`stream
+BT /F1 12 Tf 72 700 Td
+(a) Tj 1 0 0 rg (b) Tj 3 Tr (c) Tj 0 Tr 1 g (d) Tj
+2 Tr 0 0 1 0 K (e) Tj 0 Tr /CS0 cs 0.5 sc (f) Tj /F1 0 Tf (g) Tj
+ET`,
+	)
+	text := r.Page(1).Content().Text
+	assert.Equal(t, 7, len(text))
+	red, yellow := color.RGBA{255, 0, 0, 255}, color.RGBA{255, 255, 0, 255}
+	tests := []struct {
+		mode      int
+		fill      color.RGBA
+		stroke    color.RGBA
+		invisible bool
+	}{
+		{RenderFill, black, black, false},
+		{RenderFill, red, black, false},
+		{RenderInvisible, red, black, true},      // Tr 3
+		{RenderFill, white, black, true},         // white on white
+		{RenderFillStroke, white, yellow, false}, // outlined
+		{RenderFill, color.RGBA{255, 128, 255, 255}, yellow, false},
+		{RenderFill, color.RGBA{255, 128, 255, 255}, yellow, true}, // zero size
+	}
+	for i, tt := range tests {
+		assert.Equal(t, tt.mode, text[i].Mode)
+		assert.Equal(t, tt.fill, text[i].Fill)
+		assert.Equal(t, tt.stroke, text[i].Stroke)
+		assert.Equal(t, tt.invisible, text[i].Invisible)
+	}
+}
+
+func TestRowsDecodeTJ(t *testing.T) {
+	r := newTestPDF(t,
+		`<< /Type /Catalog /Pages 2 0 R >>`,
+		`<< /Type /Pages /Kids [3 0 R] /Count 1 >>`,
+		`<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 4 0 R
+		   /Resources << /Font << /F1 << /Type /Font /Subtype /Type1 /BaseFont /Helvetica
+		     /Encoding << /Differences [65 /B] >> >> >> >> >>`,
+		`stream
+BT /F1 12 Tf 1 0 0 1 72 700 Tm (A) Tj 1 0 0 1 72 680 Tm [(A) -100 <41>] TJ ET`,
+	)
+	rows, err := r.Page(1).GetTextByRow()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, len(rows))
+	assert.Equal(t, "B", rows[0].Content[0].S)
+	assert.Equal(t, "B", rows[1].Content[0].S)
+	assert.Equal(t, "B", rows[1].Content[1].S)
+}
+
+func TestSelfReferentialColorSpace(t *testing.T) {
+	r := newTestPDF(t,
+		`<< /Type /Catalog /Pages 2 0 R >>`,
+		`<< /Type /Pages /Kids [3 0 R] /Count 1 >>`,
+		`<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 4 0 R
+		   /Resources << /Font << /F1 << /Type /Font /Subtype /Type1 /BaseFont /Helvetica >> >>
+		                 /ColorSpace << /CS0 5 0 R >> >> >>`,
+		`stream
+BT /F1 12 Tf 72 700 Td /CS0 cs 0 sc (a) Tj ET`,
+		`[/Indexed 5 0 R 0 <00>]`,
+	)
+	text := r.Page(1).Content().Text
+	assert.Equal(t, 1, len(text))
+	assert.Equal(t, black, text[0].Fill)
+}
+
+func TestWalkPages(t *testing.T) {
+	r := newTestPDF(t,
+		`<< /Type /Catalog /Pages 2 0 R >>`,
+		`<< /Type /Pages /Kids [3 0 R 4 0 R 7 0 R] /Count 4 /MediaBox [0 0 612 792]
+		   /Resources << /Font << /F1 << /Type /Font /Subtype /Type1 /BaseFont /Helvetica >> >> >> >>`,
+		`<< /Type /Page /Parent 2 0 R /Contents 8 0 R >>`,
+		`<< /Type /Pages /Parent 2 0 R /Kids [5 0 R 6 0 R 4 0 R] /Count 2 >>`,
+		`<< /Type /Page /Parent 4 0 R /Contents 9 0 R >>`,
+		`<< /Type /Page /Parent 4 0 R /Contents 10 0 R >>`,
+		`<< /Type /Page /Parent 2 0 R /Contents 11 0 R >>`,
+		"stream\nBT /F1 12 Tf 72 700 Td (One) Tj ET",
+		"stream\nBT /F1 12 Tf 72 700 Td (Two) Tj ET",
+		"stream\nBT /F1 12 Tf 72 700 Td (Three) Tj ET",
+		"stream\nBT /F1 12 Tf 72 700 Td (Four) Tj ET",
+	)
+
+	// The kids of the inner node list the node itself, which must not be walked again.
+	var nums []int
+	var text []string
+	err := r.WalkPages(func(num int, p Page) error {
+		nums = append(nums, num)
+		assert.Equal(t, r.Page(num).V.ptr, p.V.ptr)
+		var s string
+		assert.Equal(t, nil, p.WalkGlyphs(func(g Glyph) { s += g.Text.S }))
+		text = append(text, s)
+		return nil
+	})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []int{1, 2, 3, 4}, nums)
+	assert.Equal(t, []string{"One", "Two", "Three", "Four"}, text)
+
+	stop := fmt.Errorf("stop")
+	nums = nil
+	err = r.WalkPages(func(num int, p Page) error {
+		nums = append(nums, num)
+		if num == 2 {
+			return stop
+		}
+		return nil
+	})
+	assert.Equal(t, stop, err)
+	assert.Equal(t, []int{1, 2}, nums)
+
+	var buf bytes.Buffer
+	assert.Equal(t, nil, r.WritePlainText(&buf))
+	assert.Equal(t, "One\nTwo\nThree\nFour\n", buf.String())
+}
+
+func TestSharedFonts(t *testing.T) {
+	r := newTestPDF(t,
+		`<< /Type /Catalog /Pages 2 0 R >>`,
+		`<< /Type /Pages /Kids [3 0 R 4 0 R] /Count 2 /MediaBox [0 0 612 792] >>`,
+		`<< /Type /Page /Parent 2 0 R /Contents 6 0 R
+		   /Resources << /Font << /F1 5 0 R /F2 << /Type /Font /Subtype /Type1 /BaseFont /Courier >> >> >> >>`,
+		`<< /Type /Page /Parent 2 0 R /Contents 7 0 R /Resources << /Font << /A 5 0 R >> >> >>`,
+		`<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>`,
+		"stream\nBT /F1 12 Tf 72 700 Td (One) Tj /F2 12 Tf (Two) Tj ET",
+		"stream\nBT /A 12 Tf 72 700 Td (Three) Tj ET",
+	)
+	caches := make(map[objptr]*fontCache)
+	one := r.Page(1).sharedFonts(caches)
+	two := r.Page(2).sharedFonts(caches)
+	assert.Equal(t, 1, len(caches)) // the direct font is not shared
+	assert.T(t, one["F1"].cache == two["A"].cache)
+	assert.T(t, one["F2"].cache != nil && one["F2"].cache != one["F1"].cache)
+
+	var buf bytes.Buffer
+	assert.Equal(t, nil, r.WritePlainText(&buf))
+	assert.Equal(t, "OneTwo\nThree\n", buf.String())
+}
@@ -45,6 +45,9 @@ func newDict() Value {
 // to implement op.
 //
 // Interpret handles the operators "dict", "currentdict", "begin", "end", "def", and "pop" itself.
+// It reads the inline images of content streams, from BI to EI, itself too,
+// calling do with the operator "BI" and with the image's dictionary, its
+// abbreviations expanded, and its raw data, as a string, on the stack.
 //
 // Interpret is not a full-blown PostScript interpreter. Its job is to handle the
 // very limited PostScript found in certain supporting file formats embedded
@@ -74,33 +77,60 @@ func Interpret(strm Value, do func(stk *Stack, op string)) {
 		}
 		b = newBuffer(ioutil.NopCloser(bytes.NewReader(data)), 0)
 	}
+	interpret(b, do)
+}
+
+// interpret is the implementation of Interpret, reading from b.
+func interpret(b *buffer, do func(stk *Stack, op string)) {
 	b.allowEOF = true
 	b.allowObjptr = false
 	b.allowStream = false
 	var stk Stack
 	var dicts []dict
-	var ignore = false
+	var ignore = 0 // the depth of marked content in an ignored sequence with Attached, or 0
 Reading:
 	for {
 		tok := b.readToken()
 		if kw, ok := tok.(keyword); ok {
 			switch kw {
 			default:
-				if ignore {
+				if ignore > 0 {
+					stk.stack = []Value{}
+					continue
+				}
+			case "BMC", "BDC":
+				if ignore > 0 {
+					ignore++
 					stk.stack = []Value{}
 					continue
 				}
-			case "BDC":
-				for _, value := range stk.stack {
-					if d, ok := value.data.(dict); ok {
-						if _, exists := d["Attached"]; exists {
-							// 直接ignore
-							ignore = true
+				if kw == "BDC" {
+					for _, value := range stk.stack {
+						if d, ok := value.data.(dict); ok {
+							if _, exists := d["Attached"]; exists {
+								// 直接ignore
+								ignore = 1
+							}
 						}
 					}
 				}
 			case "EMC":
-				ignore = false
+				if ignore > 1 {
+					ignore--
+					stk.stack = []Value{}
+					continue
+				}
+				ignore = 0 // the EMC of the ignored sequence, which was begun
+			case "BI":
+				hdr, data := b.readInlineImage()
+				if ignore > 0 {
+					stk.stack = []Value{}
+					continue
+				}
+				stk.Push(Value{nil, objptr{}, hdr})
+				stk.Push(Value{nil, objptr{}, string(data)})
+				do(&stk, "BI")
+				continue
 			}
 		}
 		if tok == io.EOF {
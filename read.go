@@ -74,6 +74,7 @@ import (
 	"os"
 	"sort"
 	"strconv"
+	"sync"
 )
 
 // A Reader is a single PDF file open for reading.
@@ -85,6 +86,13 @@ type Reader struct {
 	trailerptr objptr
 	key        []byte
 	useAES     bool
+
+	fontMu       sync.Mutex
+	fontPrograms map[objptr]*fontProgram // parsed embedded fonts, by stream
+	fontOrder    []objptr                // the keys of fontPrograms, least recently used first
+
+	optsMu sync.RWMutex
+	opts   ExtractOptions // set by SetExtractOptions
 }
 
 type xref struct {
@@ -595,21 +603,23 @@ func (v Value) Float64() float64 {
 // RawString returns v's string value.
 // If v.Kind() != String, RawString returns the empty string.
 func (v Value) RawString() string {
-	x, ok := v.data.(string)
-	if !ok {
-		return ""
+	switch x := v.data.(type) {
+	case string:
+		return x
+	case rawString:
+		return string(x)
 	}
-	return x
+	return ""
 }
 
 // Text returns v's string value interpreted as a ``text string'' (defined in the PDF spec)
 // and converted to UTF-8.
 // If v.Kind() != String, Text returns the empty string.
 func (v Value) Text() string {
-	x, ok := v.data.(string)
-	if !ok {
+	if v.Kind() != String {
 		return ""
 	}
+	x := v.RawString()
 	if isPDFDocEncoded(x) {
 		return pdfDocDecode(x)
 	}
@@ -624,10 +634,10 @@ func (v Value) Text() string {
 // If v.Kind() != String or if the data is not valid UTF-16, TextFromUTF16 returns
 // the empty string.
 func (v Value) TextFromUTF16() string {
-	x, ok := v.data.(string)
-	if !ok {
+	if v.Kind() != String {
 		return ""
 	}
+	x := v.RawString()
 	if len(x)%2 == 1 {
 		return ""
 	}
@@ -853,6 +863,12 @@ func applyFilter(rd io.Reader, filterName string, param Value, hdr dict) io.Read
 		case 12:
 			return &pngUpReader{r: zr, hist: make([]byte, 1+columns), tmp: make([]byte, 1+columns)}
 		}
+	case "ASCIIHexDecode":
+		data, err := ioutil.ReadAll(rd)
+		if err != nil {
+			panic(err)
+		}
+		return bytes.NewReader(asciiHexDecode(data))
 	case "ASCII85Decode":
 		cleanASCII85 := newAlphaReader(rd)
 		decoder := ascii85.NewDecoder(cleanASCII85)
@@ -0,0 +1,244 @@
+// Copyright 2014 The Go Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pdf
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// SearchOptions control how Reader.Search matches text.
+type SearchOptions struct {
+	Regexp           bool // the query is a regular expression, in the syntax of package regexp
+	IgnoreCase       bool // letters match regardless of case
+	IgnoreDiacritics bool // letters match regardless of accents and other marks, so that "cafe" matches "café"
+}
+
+// A SearchHit is a match for a search query.
+type SearchHit struct {
+	Page  int    // the number of the page, starting at 1
+	Text  string // the text matched, as shown on the page
+	Rects []Rect // the bounding boxes of the matched glyphs, one for each line the match spans
+}
+
+// Search finds the matches for query in the text of the document's pages,
+// in page order and, within a page, in the order of the page's text.
+//
+// The text of a page is searched as a sequence of words separated by single
+// spaces, split as by Page.Words, with line breaks read as spaces. A run of
+// whitespace in a literal query matches any break between words, so a phrase
+// matches across the end of a line, and a word hyphenated at the end of a
+// line matches without its hyphen. Compatibility characters, such
+// as ligatures, are searched as the characters they stand for.
+//
+// Search searches every page before it returns, so it holds the hits
+// for the whole document in memory, though only one page's text at a time.
+func (r *Reader) Search(query string, opts SearchOptions) ([]SearchHit, error) {
+	match, err := newMatcher(query, opts)
+	if err != nil {
+		return nil, err
+	}
+	var hits []SearchHit
+	err = r.WalkPages(func(i int, p Page) error {
+		text, err := pageText(p)
+		if err != nil {
+			return err
+		}
+		d := p.display()
+		for _, hit := range search(text, match, opts, i) {
+			for j, r := range hit.Rects {
+				hit.Rects[j] = d.flipRect(r)
+			}
+			hits = append(hits, hit)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return hits, nil
+}
+
+// pageText returns the glyphs shown on p, as Page.Content does but
+// in upright space, recovering from the panics of a malformed
+// content stream.
+func pageText(p Page) (text []Text, err error) {
+	p = p.fixOptions()
+	defer func() {
+		if r := recover(); r != nil {
+			text = nil
+			err = errors.New(fmt.Sprint(r))
+		}
+	}()
+	return p.extractOptions().normalizeText(p.content(nil).Text), nil
+}
+
+// A matcher returns the start and end offsets of the matches in s.
+type matcher func(s string) [][]int
+
+func newMatcher(query string, opts SearchOptions) (matcher, error) {
+	if opts.Regexp {
+		expr := query
+		if opts.IgnoreDiacritics {
+			expr = stripMarks(expr)
+		}
+		if opts.IgnoreCase {
+			expr = "(?i)" + expr
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, err
+		}
+		return func(s string) [][]int {
+			var out [][]int
+			for _, m := range re.FindAllStringIndex(s, -1) {
+				if m[0] < m[1] {
+					out = append(out, m)
+				}
+			}
+			return out
+		}, nil
+	}
+	q := strings.Join(strings.Fields(foldText(query, opts)), " ")
+	return func(s string) [][]int {
+		var out [][]int
+		if q == "" {
+			return nil
+		}
+		for at := 0; ; {
+			i := strings.Index(s[at:], q)
+			if i < 0 {
+				return out
+			}
+			out = append(out, []int{at + i, at + i + len(q)})
+			at += i + len(q)
+		}
+	}, nil
+}
+
+// foldText returns s in the form in which search compares it.
+// Regular expressions do their own case folding.
+func foldText(s string, opts SearchOptions) string {
+	s = norm.NFKC.String(s)
+	if opts.IgnoreDiacritics {
+		s = stripMarks(s)
+	}
+	if opts.IgnoreCase && !opts.Regexp {
+		s = strings.ToLower(s)
+	}
+	return s
+}
+
+// stripMarks removes the diacritical marks from s.
+func stripMarks(s string) string {
+	s = norm.NFD.String(s)
+	if strings.IndexFunc(s, isMark) < 0 {
+		return norm.NFC.String(s)
+	}
+	return norm.NFC.String(strings.Map(func(r rune) rune {
+		if isMark(r) {
+			return -1
+		}
+		return r
+	}, s))
+}
+
+func isMark(r rune) bool {
+	return unicode.Is(unicode.Mn, r)
+}
+
+// search returns the matches for match in the glyphs text shown on the given page.
+func search(text []Text, match matcher, opts SearchOptions, page int) []SearchHit {
+	// Build the searched text from the words of the page, recording the
+	// glyph each byte comes from, or -1 for the spaces between words,
+	// and the word of each glyph and the line of each word.
+	words, wordGlyphs := segmentWords(text)
+	var (
+		b        []byte
+		glyphs   []int
+		word     = make(map[int]int)
+		line     = make([]int, len(words))
+		joined   = make([]bool, len(words)) // whether a word continues one hyphenated at the end of a line
+		prevLine = -1                       // the line of the last word searched
+	)
+	for i, w := range words {
+		if i > 0 {
+			line[i] = line[i-1]
+			if !sameBaseline(words[i-1], w) {
+				line[i]++
+			}
+		}
+		var (
+			s  []byte
+			sg []int
+		)
+		for _, g := range wordGlyphs[i] {
+			f := foldText(text[g].S, opts)
+			s = append(s, f...)
+			for range []byte(f) {
+				sg = append(sg, g)
+			}
+			word[g] = i
+		}
+		if len(s) == 0 {
+			continue
+		}
+		if len(b) > 0 {
+			// A word hyphenated at the end of a line is searched without its hyphen.
+			first, _ := utf8.DecodeRune(s)
+			if last, n := utf8.DecodeLastRune(b); line[i] != prevLine && isHyphen(last) && unicode.IsLower(first) {
+				b, glyphs = b[:len(b)-n], glyphs[:len(glyphs)-n]
+				joined[i] = true
+			} else {
+				b = append(b, ' ')
+				glyphs = append(glyphs, -1)
+			}
+		}
+		b = append(b, s...)
+		glyphs = append(glyphs, sg...)
+		prevLine = line[i]
+	}
+
+	var hits []SearchHit
+	for _, m := range match(string(b)) {
+		hit := SearchHit{Page: page}
+		var s strings.Builder
+		prev, last := -1, -1 // the previous glyph and its line
+		for _, g := range glyphs[m[0]:m[1]] {
+			if g < 0 || g == prev {
+				continue
+			}
+			t := text[g]
+			if prev >= 0 && word[g] != word[prev] && !joined[word[g]] {
+				s.WriteString(" ")
+			}
+			s.WriteString(t.S)
+			if l := line[word[g]]; len(hit.Rects) > 0 && l == last {
+				hit.Rects[len(hit.Rects)-1] = glyphRect(hit.Rects[len(hit.Rects)-1], t)
+			} else {
+				hit.Rects = append(hit.Rects, glyphRect(Rect{}, t))
+				last = l
+			}
+			prev = g
+		}
+		if prev < 0 {
+			continue
+		}
+		hit.Text = s.String()
+		hits = append(hits, hit)
+	}
+	return hits
+}
+
+// isHyphen reports whether r is a hyphen that may break a word at the end of a line.
+func isHyphen(r rune) bool {
+	return r == '-' || r == '\u2010' || r == '\u00AD'
+}
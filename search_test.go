@@ -0,0 +1,73 @@
+package pdf
+
+import (
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+func TestSearch(t *testing.T) {
+	r := newTestPDF(t,
+		`<< /Type /Catalog /Pages 2 0 R >>`,
+		`<< /Type /Pages /Kids [3 0 R] /Count 1 >>`,
+		`<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 4 0 R
+		   /Resources << /Font << /F1 << /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >> >> >> >>`,
+		`stream
+BT /F1 10 Tf
+1 0 0 1 100 700 Tm (The quick brown) Tj
+1 0 0 1 100 688 Tm (fox at the Caf\351, an exam-) Tj
+1 0 0 1 100 676 Tm (ple) Tj
+ET`,
+	)
+	texts := func(hits []SearchHit) []string {
+		var out []string
+		for _, h := range hits {
+			assert.Equal(t, 1, h.Page)
+			out = append(out, h.Text)
+		}
+		return out
+	}
+
+	hits, err := r.Search("brown  fox", SearchOptions{})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{"brown fox"}, texts(hits))
+	// Courier glyphs are 6 points wide, with ascent 629 and descent -157.
+	assert.Equal(t, 2, len(hits[0].Rects))
+	assert.Equal(t, Rect{Point{160, 698.43}, Point{190, 706.29}}, hits[0].Rects[0])
+	assert.Equal(t, Rect{Point{100, 686.43}, Point{118, 694.29}}, hits[0].Rects[1])
+
+	hits, _ = r.Search("CAFE", SearchOptions{})
+	assert.Equal(t, 0, len(hits))
+	hits, _ = r.Search("CAFE", SearchOptions{IgnoreCase: true, IgnoreDiacritics: true})
+	assert.Equal(t, []string{"Café"}, texts(hits))
+
+	hits, _ = r.Search("example", SearchOptions{})
+	assert.Equal(t, []string{"example"}, texts(hits))
+	assert.Equal(t, 2, len(hits[0].Rects))
+
+	hits, _ = r.Search(`th\w`, SearchOptions{Regexp: true, IgnoreCase: true})
+	assert.Equal(t, []string{"The", "the"}, texts(hits))
+
+	_, err = r.Search(`(`, SearchOptions{Regexp: true})
+	assert.NotEqual(t, nil, err)
+}
+
+func TestSearchWords(t *testing.T) {
+	// Text is searched in words as Page.Words gives them.
+	var text []Text
+	text = append(text, upwardGlyphs("Side", 100, 200)...)
+	text = append(text, upwardGlyphs("note", 100, 222)...)
+	text = append(text, rtlGlyphs("םלוע םולש", 300, 6)...) // shown left to right
+
+	match, err := newMatcher("side note", SearchOptions{IgnoreCase: true})
+	assert.Equal(t, nil, err)
+	hits := search(text, match, SearchOptions{IgnoreCase: true}, 1)
+	assert.Equal(t, 1, len(hits))
+	assert.Equal(t, "Side note", hits[0].Text)
+	assert.Equal(t, []Rect{{Point{92, 200}, Point{102, 242}}}, hits[0].Rects)
+
+	match, _ = newMatcher("שלום עולם", SearchOptions{})
+	hits = search(text, match, SearchOptions{}, 1)
+	assert.Equal(t, 1, len(hits))
+	assert.Equal(t, "שלום עולם", hits[0].Text)
+}
@@ -0,0 +1,263 @@
+// Copyright 2014 The Go Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Logical structure of tagged PDF files. See PDF 32000-1:2008, §14.7 and §14.8.
+
+package pdf
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// A StructElem is an element of the logical structure tree of a tagged PDF.
+type StructElem struct {
+	Type       string // the standard structure type, such as Document, H1, P, L, LI, Table, TR, TD or Figure
+	Role       string // the structure type as written in the file, before role mapping
+	Title      string // the element's title (T entry)
+	Lang       string // the language of the element's content
+	Alt        string // the alternate description, as given for a Figure
+	ActualText string // a replacement for the text of the element's content
+	Text       string // the text of the element's marked content, including that of its descendants
+	Kids       []*StructElem
+}
+
+// StructTree returns the top-level elements of the document's logical
+// structure tree, typically a single Document element, or nil if the
+// document is not tagged.
+//
+// The text of each element is gathered from the marked-content sequences
+// whose MCIDs its K entry lists, in the order it lists them, and is spaced
+// into words and lines as by Page.Words. It is the text as the fonts encode
+// it, in the layers visible by default, whatever the extraction options.
+// A marked-content reference naming a form XObject's stream (Stm) is
+// looked up among the sequences of that form as painted on its page.
+// Object references (OBJR), such as to annotations, add no text.
+// An element listed more than once in the tree is converted where it
+// is first listed.
+//
+// StructTree gathers the text of every page before it returns,
+// so it holds the text of the whole document in memory.
+func (r *Reader) StructTree() []*StructElem {
+	root := r.Trailer().Key("Root").Key("StructTreeRoot")
+	if root.Kind() != Dict {
+		return nil
+	}
+	w := &structWalker{
+		roleMap:  root.Key("RoleMap"),
+		pages:    make(map[objptr]Page),
+		glyphs:   make(map[objptr]map[markedID][]Text),
+		elemPage: make(map[objptr]objptr),
+		seen:     make(map[objptr]bool),
+	}
+	r.WalkPages(func(i int, p Page) error {
+		w.pages[p.V.ptr] = p
+		if n := p.V.Key("StructParents"); n.Kind() == Integer {
+			elems := numberTreeLookup(root.Key("ParentTree"), int(n.Int64()))
+			for j := 0; j < elems.Len(); j++ {
+				if e := elems.Index(j); e.Kind() == Dict {
+					w.elemPage[e.ptr] = p.V.ptr
+				}
+			}
+		}
+		return nil
+	})
+
+	var out []*StructElem
+	forEachKid(root.Key("K"), func(k Value) {
+		if k.Kind() == Dict {
+			if e, _ := w.walk(k, Value{}, 0); e != nil {
+				out = append(out, e)
+			}
+		}
+	})
+	return out
+}
+
+// structWalker holds the state of a walk over the structure tree.
+type structWalker struct {
+	roleMap  Value
+	pages    map[objptr]Page                // pages by object
+	glyphs   map[objptr]map[markedID][]Text // text shown on each page, by MCID
+	elemPage map[objptr]objptr              // page of each element listed in the ParentTree
+	seen     map[objptr]bool                // the elements converted, so that shared or cyclic kids are converted once
+}
+
+// A markedID identifies a marked-content sequence on a page: its MCID
+// and the form XObject whose content stream holds it, or zero for the
+// page's contents.
+type markedID struct {
+	stm  objptr
+	mcid int
+}
+
+// maxStructDepth limits the depth of the structure tree,
+// guarding against deep nesting in malformed files.
+const maxStructDepth = 100
+
+// walk converts the structure element e, whose ancestors give pg as the
+// default page, and returns it along with the text its content shows.
+func (w *structWalker) walk(e, pg Value, depth int) (*StructElem, []Text) {
+	if depth > maxStructDepth || e.Key("Type").Name() == "OBJR" || e.Key("Type").Name() == "MCR" {
+		return nil, nil
+	}
+	if e.ptr != (objptr{}) {
+		if w.seen[e.ptr] {
+			return nil, nil
+		}
+		w.seen[e.ptr] = true
+	}
+	if p := e.Key("Pg"); p.Kind() == Dict {
+		pg = p
+	}
+	role := e.Key("S").Name()
+	elem := &StructElem{
+		Type:       w.standardType(role),
+		Role:       role,
+		Title:      e.Key("T").Text(),
+		Lang:       e.Key("Lang").Text(),
+		Alt:        e.Key("Alt").Text(),
+		ActualText: e.Key("ActualText").Text(),
+	}
+	var text []Text
+	forEachKid(e.Key("K"), func(k Value) {
+		switch k.Kind() {
+		case Integer: // an MCID on the element's page
+			text = append(text, w.content(w.page(e, pg), markedID{mcid: int(k.Int64())})...)
+		case Dict:
+			switch k.Key("Type").Name() {
+			case "MCR": // a marked-content reference, perhaps on another page
+				kpg := pg
+				if p := k.Key("Pg"); p.Kind() == Dict {
+					kpg = p
+				}
+				id := markedID{mcid: int(k.Key("MCID").Int64())}
+				if stm := k.Key("Stm"); !stm.IsNull() {
+					if stm.ptr == (objptr{}) {
+						return // a direct stream cannot be painted as a form
+					}
+					id.stm = stm.ptr
+				}
+				text = append(text, w.content(w.page(e, kpg), id)...)
+			case "OBJR": // an object reference, such as an annotation; it adds no text
+			default:
+				if kid, t := w.walk(k, pg, depth+1); kid != nil {
+					elem.Kids = append(elem.Kids, kid)
+					text = append(text, t...)
+				}
+			}
+		}
+	})
+	elem.Text = strings.TrimSuffix(plainText(words(text)), "\n")
+	return elem, text
+}
+
+// page returns the page holding the content of element e: pg if
+// known, or else the page the ParentTree associates with e.
+func (w *structWalker) page(e, pg Value) objptr {
+	if pg.Kind() == Dict {
+		return pg.ptr
+	}
+	return w.elemPage[e.ptr]
+}
+
+// content returns the text shown by the marked-content sequence
+// id on the page with object pointer pg.
+func (w *structWalker) content(pg objptr, id markedID) []Text {
+	byID, ok := w.glyphs[pg]
+	if !ok {
+		byID = make(map[markedID][]Text)
+		if p, ok := w.pages[pg]; ok {
+			text, _ := markedText(p)
+			for _, t := range text {
+				if mcid, stm := t.Marked.mcid(); mcid >= 0 {
+					id := markedID{stm, mcid}
+					byID[id] = append(byID[id], t)
+				}
+			}
+		}
+		w.glyphs[pg] = byID
+	}
+	return byID[id]
+}
+
+// markedText returns the glyphs shown on p, in user space and without
+// the changes the extraction options make, recovering from the panics
+// of a malformed content stream.
+func markedText(p Page) (text []Text, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			text = nil
+			err = errors.New(fmt.Sprint(r))
+		}
+	}()
+	return p.WithExtractOptions(ExtractOptions{}).content(nil).Text, nil
+}
+
+// standardType maps a structure type through the RoleMap
+// until it reaches one of the standard structure types.
+func (w *structWalker) standardType(s string) string {
+	for i := 0; i < 10 && !standardStructTypes[s]; i++ {
+		mapped := w.roleMap.Key(s).Name()
+		if mapped == "" {
+			break
+		}
+		s = mapped
+	}
+	return s
+}
+
+// standardStructTypes lists the standard structure types.
+// See PDF 32000-1:2008, §14.8.4.
+var standardStructTypes = map[string]bool{
+	"Document": true, "Part": true, "Art": true, "Sect": true, "Div": true,
+	"BlockQuote": true, "Caption": true, "TOC": true, "TOCI": true, "Index": true,
+	"NonStruct": true, "Private": true,
+	"P": true, "H": true, "H1": true, "H2": true, "H3": true, "H4": true, "H5": true, "H6": true,
+	"L": true, "LI": true, "Lbl": true, "LBody": true,
+	"Table": true, "TR": true, "TH": true, "TD": true, "THead": true, "TBody": true, "TFoot": true,
+	"Span": true, "Quote": true, "Note": true, "Reference": true, "BibEntry": true, "Code": true,
+	"Link": true, "Annot": true, "Ruby": true, "RB": true, "RT": true, "RP": true,
+	"Warichu": true, "WT": true, "WP": true,
+	"Figure": true, "Formula": true, "Form": true,
+}
+
+// forEachKid calls fn for k, or for each element of k if it is an array.
+func forEachKid(k Value, fn func(Value)) {
+	if k.Kind() != Array {
+		if !k.IsNull() {
+			fn(k)
+		}
+		return
+	}
+	for i := 0; i < k.Len(); i++ {
+		fn(k.Index(i))
+	}
+}
+
+// numberTreeLookup returns the value for key in the number tree t.
+// See PDF 32000-1:2008, §7.9.7.
+func numberTreeLookup(t Value, key int) Value {
+	for depth := 0; depth < maxStructDepth && t.Kind() == Dict; depth++ {
+		nums := t.Key("Nums")
+		for i := 0; i+1 < nums.Len(); i += 2 {
+			if int(nums.Index(i).Int64()) == key {
+				return nums.Index(i + 1)
+			}
+		}
+		kids := t.Key("Kids")
+		next := Value{}
+		for i := 0; i < kids.Len(); i++ {
+			kid := kids.Index(i)
+			limits := kid.Key("Limits")
+			if limits.Len() != 2 || int(limits.Index(0).Int64()) <= key && key <= int(limits.Index(1).Int64()) {
+				next = kid
+				break
+			}
+		}
+		t = next
+	}
+	return Value{}
+}
@@ -0,0 +1,113 @@
+package pdf
+
+import (
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+func TestStructTree(t *testing.T) {
+	r := newTestPDF(t,
+		`<< /Type /Catalog /Pages 2 0 R /StructTreeRoot 5 0 R /MarkInfo << /Marked true >> >>`,
+		`<< /Type /Pages /Kids [3 0 R] /Count 1 >>`,
+		`<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 4 0 R /StructParents 0
+		   /Resources << /Font << /F1 << /Type /Font /Subtype /Type1 /BaseFont /Helvetica >> >> >> >>`,
+		`stream
+BT /F1 12 Tf 72 720 Td
+/H1 << /MCID 0 >> BDC (Intro) Tj EMC
+0 -20 Td /P << /MCID 1 >> BDC [(Hello) -300 (world)] TJ EMC
+/Artifact BMC (42) Tj EMC
+0 -20 Td /Figure << /MCID 2 >> BDC (x) Tj EMC
+ET`,
+		`<< /Type /StructTreeRoot /K 6 0 R /ParentTree 10 0 R /RoleMap << /Heading /H1 >> >>`,
+		`<< /Type /StructElem /S /Document /P 5 0 R /K [7 0 R 8 0 R 9 0 R] >>`,
+		`<< /Type /StructElem /S /Heading /P 6 0 R /Pg 3 0 R /K 0 >>`,
+		`<< /Type /StructElem /S /P /P 6 0 R /Pg 3 0 R /K [<< /Type /MCR /MCID 1 >>] >>`,
+		`<< /Type /StructElem /S /Figure /P 6 0 R /K 2 /Alt (A cross) >>`,
+		`<< /Nums [0 [7 0 R 8 0 R 9 0 R]] >>`,
+	)
+	tree := r.StructTree()
+	assert.Equal(t, 1, len(tree))
+	doc := tree[0]
+	assert.Equal(t, "Document", doc.Type)
+	assert.Equal(t, "Intro\nHello world\nx", doc.Text)
+	assert.Equal(t, 3, len(doc.Kids))
+
+	h, p, fig := doc.Kids[0], doc.Kids[1], doc.Kids[2]
+	assert.Equal(t, "H1", h.Type)
+	assert.Equal(t, "Heading", h.Role)
+	assert.Equal(t, "Intro", h.Text)
+	assert.Equal(t, "P", p.Type)
+	assert.Equal(t, "Hello world", p.Text)
+	assert.Equal(t, "Figure", fig.Type)
+	assert.Equal(t, "A cross", fig.Alt)
+	assert.Equal(t, "x", fig.Text) // found through the ParentTree, having no Pg
+}
+
+func TestStructTreeShared(t *testing.T) {
+	r := newTestPDF(t,
+		`<< /Type /Catalog /Pages 2 0 R /StructTreeRoot 5 0 R /MarkInfo << /Marked true >> >>`,
+		`<< /Type /Pages /Kids [3 0 R] /Count 1 >>`,
+		`<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 4 0 R
+		   /Resources << /Font << /F1 << /Type /Font /Subtype /Type1 /BaseFont /Helvetica >> >> >> >>`,
+		`stream
+BT /F1 12 Tf 72 720 Td
+/P << /MCID 0 >> BDC
+/Artifact << /Type /Pagination /Attached [/Top] >> BDC /Span BMC (Header) Tj EMC EMC
+(\256ne) Tj EMC
+ET`,
+		`<< /Type /StructTreeRoot /K 6 0 R >>`,
+		`<< /Type /StructElem /S /Document /K [7 0 R 7 0 R 7 0 R] >>`,
+		`<< /Type /StructElem /S /Sect /K [8 0 R 8 0 R 8 0 R] >>`,
+		`<< /Type /StructElem /S /Div /K [7 0 R 9 0 R 8 0 R] >>`,
+		`<< /Type /StructElem /S /P /Pg 3 0 R /K 0 >>`,
+	)
+
+	// Elements listed more than once, or in a cycle, are converted once.
+	// The pagination artifact nested in the paragraph is left out, and
+	// the paragraph's text continues after it.
+	check := func() {
+		tree := r.StructTree()
+		assert.Equal(t, 1, len(tree))
+		doc := tree[0]
+		assert.Equal(t, 1, len(doc.Kids))
+		sect := doc.Kids[0]
+		assert.Equal(t, 1, len(sect.Kids))
+		div := sect.Kids[0]
+		assert.Equal(t, 1, len(div.Kids))
+		assert.Equal(t, "P", div.Kids[0].Type)
+		assert.Equal(t, "ﬁne", doc.Text)
+	}
+	check()
+
+	// The extraction options do not change the structure tree.
+	r.SetExtractOptions(ExtractOptions{ExpandLigatures: true, DisplaySpace: true})
+	check()
+}
+
+func TestStructTreeForm(t *testing.T) {
+	// The page and the form it paints both number a sequence MCID 0.
+	r := newTestPDF(t,
+		`<< /Type /Catalog /Pages 2 0 R /StructTreeRoot 6 0 R /MarkInfo << /Marked true >> >>`,
+		`<< /Type /Pages /Kids [3 0 R] /Count 1 >>`,
+		`<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 4 0 R
+		   /Resources << /Font << /F1 << /Type /Font /Subtype /Type1 /BaseFont /Helvetica >> >>
+		                 /XObject << /Fm1 5 0 R >> >> >>`,
+		`stream
+BT /F1 12 Tf 72 720 Td /P << /MCID 0 >> BDC (Page) Tj EMC ET
+/Fm1 Do`,
+		`<< /Type /XObject /Subtype /Form /BBox [0 0 612 792]
+		   /Resources << /Font << /F1 << /Type /Font /Subtype /Type1 /BaseFont /Helvetica >> >> >> >>
+stream
+BT /F1 12 Tf 72 600 Td /P << /MCID 0 >> BDC (Form) Tj EMC ET`,
+		`<< /Type /StructTreeRoot /K [7 0 R 8 0 R 9 0 R] >>`,
+		`<< /Type /StructElem /S /P /Pg 3 0 R /K 0 >>`,
+		`<< /Type /StructElem /S /P /Pg 3 0 R /K << /Type /MCR /MCID 0 /Stm 5 0 R >> >>`,
+		`<< /Type /StructElem /S /Link /Pg 3 0 R /K << /Type /OBJR /Obj 3 0 R >> >>`,
+	)
+	tree := r.StructTree()
+	assert.Equal(t, 3, len(tree))
+	assert.Equal(t, "Page", tree[0].Text)
+	assert.Equal(t, "Form", tree[1].Text)
+	assert.Equal(t, "", tree[2].Text)
+}
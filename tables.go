@@ -0,0 +1,482 @@
+// Copyright 2014 The Go Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pdf
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"math"
+	"sort"
+	"strings"
+)
+
+// A Table is a grid of cells found on a page.
+type Table struct {
+	Rect  Rect
+	Cols  int  // the number of columns in the grid
+	Ruled bool // whether the table was found from ruling lines, rather than from the alignment of its text
+	Rows  []TableRow
+}
+
+// A TableRow is a row of a table's grid. It holds the cells that begin in it.
+type TableRow struct {
+	Rect  Rect
+	Cells []TableCell
+}
+
+// A TableCell is a cell of a table, which may span several rows and
+// columns of the grid when cells are merged.
+type TableCell struct {
+	Row, Col         int // the grid position of the cell's top left corner, counting from 0
+	RowSpan, ColSpan int // the number of rows and columns the cell covers
+	Rect             Rect
+	Text             string // the text of the words in the cell; lines are separated by newlines
+}
+
+// A segment is a straight line drawn on the page, in page space.
+type segment struct {
+	p, q Point
+}
+
+// Thresholds for finding tables, in points.
+const (
+	ruleSnap   = 2.0 // lines this close are taken to be at the same position
+	minRuleLen = 4.0 // shorter lines are not table rules
+)
+
+// Tables returns the tables on the page, top to bottom.
+//
+// Tables are found in two ways. Ruled tables are grids of horizontal and
+// vertical lines, drawn as line segments or as rectangles, which may be
+// thin filled rectangles or the borders of shaded cells; where a rule
+// between two cells of the grid is missing, the cells are merged. The
+// remaining text is then searched for aligned tables: runs of at least
+// three lines each divided into two or more parts by wide gaps, with the
+// parts of different lines lined up in columns. A part spanning several
+// columns, such as a heading, makes a merged cell.
+//
+// The text of each cell is that of the words whose centers lie in it.
+func (p Page) Tables() []Table {
+	p = p.fixOptions()
+	c := p.content(nil)
+	tables := findTables(c.rules, p.extractOptions().finishWords(words(c.Text)))
+	p.display().flipTables(tables)
+	return tables
+}
+
+// findTables returns the tables drawn by the lines rules and set by the
+// words ws, top to bottom, in upright space.
+func findTables(rules []segment, ws []Word) []Table {
+	tables := ruledTables(rules, ws)
+
+	var rest []Word
+	for _, w := range ws {
+		in := false
+		for _, t := range tables {
+			in = in || contains(t.Rect, center(w.Rect))
+		}
+		if !in {
+			rest = append(rest, w)
+		}
+	}
+	tables = append(tables, alignedTables(rest)...)
+	sort.SliceStable(tables, func(i, j int) bool {
+		return tables[i].Rect.Max.Y > tables[j].Rect.Max.Y
+	})
+	return tables
+}
+
+// WriteCSV writes the table's grid to w as comma-separated values.
+// A merged cell's text is written at its top left position,
+// and the other positions it covers are left empty.
+func (t Table) WriteCSV(w io.Writer) error {
+	grid := make([][]string, len(t.Rows))
+	for i := range grid {
+		grid[i] = make([]string, t.Cols)
+	}
+	for _, row := range t.Rows {
+		for _, c := range row.Cells {
+			if c.Row < len(grid) && c.Col < t.Cols {
+				grid[c.Row][c.Col] = c.Text
+			}
+		}
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.WriteAll(grid); err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+// WriteJSON writes the table to w as a JSON object.
+func (t Table) WriteJSON(w io.Writer) error {
+	return json.NewEncoder(w).Encode(t)
+}
+
+// ruledTables returns the tables whose grids are drawn by the lines rules.
+func ruledTables(rules []segment, words []Word) []Table {
+	var hs, vs []segment // horizontal lines from left to right, vertical lines from bottom to top
+	for _, s := range rules {
+		switch {
+		case math.Abs(s.p.Y-s.q.Y) <= ruleSnap/2 && math.Abs(s.p.X-s.q.X) >= minRuleLen:
+			y := (s.p.Y + s.q.Y) / 2
+			hs = append(hs, segment{Point{math.Min(s.p.X, s.q.X), y}, Point{math.Max(s.p.X, s.q.X), y}})
+		case math.Abs(s.p.X-s.q.X) <= ruleSnap/2 && math.Abs(s.p.Y-s.q.Y) >= minRuleLen:
+			x := (s.p.X + s.q.X) / 2
+			vs = append(vs, segment{Point{x, math.Min(s.p.Y, s.q.Y)}, Point{x, math.Max(s.p.Y, s.q.Y)}})
+		}
+	}
+	hs = mergeRules(hs, func(p Point) (float64, float64) { return p.Y, p.X })
+	vs = mergeRules(vs, func(p Point) (float64, float64) { return p.X, p.Y })
+
+	// Group the lines that cross or touch one another.
+	parent := make([]int, len(hs)+len(vs))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	for i, h := range hs {
+		for j, v := range vs {
+			if v.p.Y-ruleSnap <= h.p.Y && h.p.Y <= v.q.Y+ruleSnap && h.p.X-ruleSnap <= v.p.X && v.p.X <= h.q.X+ruleSnap {
+				parent[find(i)] = find(len(hs) + j)
+			}
+		}
+	}
+	groups := make(map[int][2][]segment)
+	var roots []int
+	for i := range parent {
+		r := find(i)
+		g, ok := groups[r]
+		if !ok {
+			roots = append(roots, r)
+		}
+		if i < len(hs) {
+			g[0] = append(g[0], hs[i])
+		} else {
+			g[1] = append(g[1], vs[i-len(hs)])
+		}
+		groups[r] = g
+	}
+
+	var tables []Table
+	for _, r := range roots {
+		g := groups[r]
+		if len(g[0]) < 2 || len(g[1]) < 2 {
+			continue
+		}
+		if t, ok := ruledTable(g[0], g[1], words); ok {
+			tables = append(tables, t)
+		}
+	}
+	return tables
+}
+
+// mergeRules joins the overlapping collinear lines in rules, where pos
+// returns a point's position across the lines and along them.
+func mergeRules(rules []segment, pos func(Point) (across, along float64)) []segment {
+	sort.Slice(rules, func(i, j int) bool {
+		ai, li := pos(rules[i].p)
+		aj, lj := pos(rules[j].p)
+		if math.Abs(ai-aj) > ruleSnap/2 {
+			return ai < aj
+		}
+		return li < lj
+	})
+	var out []segment
+	for _, s := range rules {
+		if n := len(out); n > 0 {
+			last := &out[n-1]
+			la, _ := pos(last.p)
+			sa, sp := pos(s.p)
+			_, lq := pos(last.q)
+			if math.Abs(la-sa) <= ruleSnap/2 && sp <= lq+ruleSnap {
+				if _, sq := pos(s.q); sq > lq {
+					last.q = s.q
+				}
+				continue
+			}
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+// ruledTable builds the table drawn by the horizontal lines hs
+// and vertical lines vs.
+func ruledTable(hs, vs []segment, words []Word) (Table, bool) {
+	var xs, ys []float64
+	for _, v := range vs {
+		xs = append(xs, v.p.X)
+	}
+	for _, h := range hs {
+		ys = append(ys, h.p.Y)
+	}
+	xs, ys = cluster(xs), cluster(ys)
+	if len(xs) < 2 || len(ys) < 2 {
+		return Table{}, false
+	}
+	sort.Sort(sort.Reverse(sort.Float64Slice(ys))) // top to bottom
+
+	// covered reports whether one of the lines covers the point along it.
+	covered := func(lines []segment, across, along float64, vertical bool) bool {
+		for _, l := range lines {
+			a, lo, hi := l.p.Y, l.p.X, l.q.X
+			if vertical {
+				a, lo, hi = l.p.X, l.p.Y, l.q.Y
+			}
+			if math.Abs(a-across) <= ruleSnap && lo-ruleSnap <= along && along <= hi+ruleSnap {
+				return true
+			}
+		}
+		return false
+	}
+
+	nrows, ncols := len(ys)-1, len(xs)-1
+	t := Table{
+		Rect:  Rect{Point{xs[0], ys[nrows]}, Point{xs[ncols], ys[0]}},
+		Cols:  ncols,
+		Ruled: true,
+		Rows:  make([]TableRow, nrows),
+	}
+	taken := make([][]bool, nrows)
+	for r := range taken {
+		taken[r] = make([]bool, ncols)
+		t.Rows[r].Rect = Rect{Point{xs[0], ys[r+1]}, Point{xs[ncols], ys[r]}}
+	}
+	for r := 0; r < nrows; r++ {
+		for c := 0; c < ncols; c++ {
+			if taken[r][c] {
+				continue
+			}
+			cs := 1
+			for c+cs < ncols && !taken[r][c+cs] && !covered(vs, xs[c+cs], (ys[r]+ys[r+1])/2, true) {
+				cs++
+			}
+			rs := 1
+		rows:
+			for r+rs < nrows {
+				for k := c; k < c+cs; k++ {
+					if taken[r+rs][k] || covered(hs, ys[r+rs], (xs[k]+xs[k+1])/2, false) {
+						break rows
+					}
+				}
+				rs++
+			}
+			for i := r; i < r+rs; i++ {
+				for k := c; k < c+cs; k++ {
+					taken[i][k] = true
+				}
+			}
+			cell := TableCell{
+				Row: r, Col: c, RowSpan: rs, ColSpan: cs,
+				Rect: Rect{Point{xs[c], ys[r+rs]}, Point{xs[c+cs], ys[r]}},
+			}
+			cell.Text = cellText(cell.Rect, words)
+			t.Rows[r].Cells = append(t.Rows[r].Cells, cell)
+		}
+	}
+	return t, true
+}
+
+// cluster sorts the positions xs and merges those within ruleSnap
+// of one another into their mean.
+func cluster(xs []float64) []float64 {
+	sort.Float64s(xs)
+	var out []float64
+	n := 0
+	for i, x := range xs {
+		if i > 0 && x-xs[i-1] <= ruleSnap {
+			out[len(out)-1] = (out[len(out)-1]*float64(n) + x) / float64(n+1)
+			n++
+			continue
+		}
+		out = append(out, x)
+		n = 1
+	}
+	return out
+}
+
+// alignedTables returns the tables formed by text aligned in columns.
+func alignedTables(words []Word) []Table {
+	// Rows are groups of lines, in the sense of Layout,
+	// sharing a baseline; their lines are the parts of each row.
+	lines := buildLines(words)
+	sort.SliceStable(lines, func(i, j int) bool {
+		return lines[i].Words[0].Y > lines[j].Words[0].Y
+	})
+	var rows [][]Line
+	for _, l := range lines {
+		if n := len(rows); n > 0 {
+			last := rows[n-1][0]
+			if math.Abs(last.Words[0].Y-l.Words[0].Y) <= wordShift*math.Max(lineSize(last), lineSize(l)) {
+				rows[n-1] = append(rows[n-1], l)
+				continue
+			}
+		}
+		rows = append(rows, []Line{l})
+	}
+	for _, row := range rows {
+		sort.Slice(row, func(i, j int) bool { return row[i].Rect.Min.X < row[j].Rect.Min.X })
+	}
+
+	// Candidate tables are runs of closely spaced rows of several parts.
+	var tables []Table
+	for i := 0; i < len(rows); {
+		j := i
+		for j < len(rows) && len(rows[j]) >= 2 {
+			if j > i {
+				size := math.Max(lineSize(rows[j-1][0]), lineSize(rows[j][0]))
+				if rows[j-1][0].Words[0].Y-rows[j][0].Words[0].Y > tableRowGap*size {
+					break
+				}
+			}
+			j++
+		}
+		if j-i >= 3 {
+			if t, ok := alignedTable(rows[i:j]); ok {
+				tables = append(tables, t)
+			}
+		}
+		if j == i {
+			j++
+		}
+		i = j
+	}
+	return tables
+}
+
+// Thresholds for aligned tables.
+const (
+	tableRowGap  = 2.5  // rows further apart than this, as a multiple of the font size, are in different tables
+	maxColumnFit = 0.35 // text columns whose parts average more than this fraction of the table's width are prose
+)
+
+// alignedTable builds a table from rows of parts lined up in columns.
+// The columns are those of the rows with the most parts; parts of other
+// rows become cells spanning the columns they overlap.
+func alignedTable(rows [][]Line) (Table, bool) {
+	most := 0
+	for _, row := range rows {
+		if len(row) > most {
+			most = len(row)
+		}
+	}
+	cols := make([]Rect, most)
+	first := true
+	for _, row := range rows {
+		if len(row) != most {
+			continue
+		}
+		for k, l := range row {
+			if first {
+				cols[k] = l.Rect
+			} else {
+				cols[k] = cols[k].union(l.Rect)
+			}
+		}
+		first = false
+	}
+	for k := 1; k < most; k++ {
+		if cols[k].Min.X <= cols[k-1].Max.X { // the columns do not line up
+			return Table{}, false
+		}
+	}
+
+	t := Table{Cols: most}
+	widths := make([]float64, most)
+	counts := make([]int, most)
+	for r, row := range rows {
+		tr := TableRow{Rect: row[0].Rect}
+		for _, l := range row {
+			tr.Rect = tr.Rect.union(l.Rect)
+			lo, hi := -1, -1
+			for k, c := range cols {
+				if l.Rect.Min.X < c.Max.X && l.Rect.Max.X > c.Min.X {
+					if lo < 0 {
+						lo = k
+					}
+					hi = k
+				}
+			}
+			if lo < 0 { // in the gutter between columns
+				return Table{}, false
+			}
+			if lo == hi {
+				widths[lo] += l.Rect.Max.X - l.Rect.Min.X
+				counts[lo]++
+			}
+			tr.Cells = append(tr.Cells, TableCell{
+				Row: r, Col: lo, RowSpan: 1, ColSpan: hi - lo + 1,
+				Rect: l.Rect,
+				Text: cellText(l.Rect, l.Words),
+			})
+		}
+		t.Rows = append(t.Rows, tr)
+		if r == 0 {
+			t.Rect = tr.Rect
+		} else {
+			t.Rect = t.Rect.union(tr.Rect)
+		}
+	}
+
+	// Reject columns of prose set side by side.
+	wide := 0
+	for k := range cols {
+		if counts[k] > 0 && widths[k]/float64(counts[k]) > maxColumnFit*(t.Rect.Max.X-t.Rect.Min.X) {
+			wide++
+		}
+	}
+	if wide >= 2 {
+		return Table{}, false
+	}
+
+	// Give each cell the full width of its columns and height of its row.
+	for r := range t.Rows {
+		row := &t.Rows[r]
+		row.Rect.Min.X, row.Rect.Max.X = t.Rect.Min.X, t.Rect.Max.X
+		for i := range row.Cells {
+			c := &row.Cells[i]
+			c.Rect = Rect{
+				Point{math.Min(cols[c.Col].Min.X, c.Rect.Min.X), row.Rect.Min.Y},
+				Point{math.Max(cols[c.Col+c.ColSpan-1].Max.X, c.Rect.Max.X), row.Rect.Max.Y},
+			}
+		}
+	}
+	return t, true
+}
+
+// cellText returns the text of the words whose centers lie in r.
+func cellText(r Rect, words []Word) string {
+	var in []Word
+	for _, w := range words {
+		if contains(r, center(w.Rect)) {
+			in = append(in, w)
+		}
+	}
+	var lines []string
+	for _, l := range buildLines(in) {
+		var ws []string
+		for _, w := range l.Words {
+			ws = append(ws, w.S)
+		}
+		lines = append(lines, strings.Join(ws, " "))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func center(r Rect) Point {
+	return Point{(r.Min.X + r.Max.X) / 2, (r.Min.Y + r.Max.Y) / 2}
+}
+
+func contains(r Rect, p Point) bool {
+	return r.Min.X <= p.X && p.X <= r.Max.X && r.Min.Y <= p.Y && p.Y <= r.Max.Y
+}
@@ -0,0 +1,51 @@
+package pdf
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+func TestTables(t *testing.T) {
+	r := newTestPDF(t,
+		`<< /Type /Catalog /Pages 2 0 R >>`,
+		`<< /Type /Pages /Kids [3 0 R] /Count 1 >>`,
+		`<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 4 0 R
+		   /Resources << /Font << /F1 << /Type /Font /Subtype /Type1 /BaseFont /Helvetica >> >> >> >>`,
+		`stream
+50 600 300 90 re S
+50 660 m 350 660 l 50 630 m 350 630 l S
+150 600 m 150 690 l S 250 600 m 250 660 l S
+BT /F1 10 Tf
+1 0 0 1 60 670 Tm (Name) Tj 1 0 0 1 160 670 Tm (Scores) Tj
+1 0 0 1 60 640 Tm (Alice) Tj 1 0 0 1 160 640 Tm (1) Tj 1 0 0 1 260 640 Tm (2) Tj
+1 0 0 1 60 610 Tm (Bob) Tj 1 0 0 1 160 610 Tm (3) Tj 1 0 0 1 260 610 Tm (4) Tj
+1 0 0 1 50 500 Tm (Item) Tj 1 0 0 1 200 500 Tm (Qty) Tj 1 0 0 1 300 500 Tm (Price) Tj
+1 0 0 1 50 486 Tm (Pencil) Tj 1 0 0 1 200 486 Tm (12) Tj 1 0 0 1 300 486 Tm (0.50) Tj
+1 0 0 1 50 472 Tm (Ink, black) Tj 1 0 0 1 200 472 Tm (3) Tj 1 0 0 1 300 472 Tm (4.25) Tj
+1 0 0 1 50 400 Tm (A closing remark.) Tj
+ET`,
+	)
+	tables := r.Page(1).Tables()
+	assert.Equal(t, 2, len(tables))
+
+	ruled := tables[0]
+	assert.Equal(t, true, ruled.Ruled)
+	assert.Equal(t, Rect{Point{50, 600}, Point{350, 690}}, ruled.Rect)
+	assert.Equal(t, TableCell{Row: 0, Col: 1, RowSpan: 1, ColSpan: 2, Rect: Rect{Point{150, 660}, Point{350, 690}}, Text: "Scores"}, ruled.Rows[0].Cells[1])
+	var buf bytes.Buffer
+	assert.Equal(t, nil, ruled.WriteCSV(&buf))
+	assert.Equal(t, "Name,Scores,\nAlice,1,2\nBob,3,4\n", buf.String())
+
+	aligned := tables[1]
+	assert.Equal(t, false, aligned.Ruled)
+	assert.Equal(t, 3, aligned.Cols)
+	buf.Reset()
+	assert.Equal(t, nil, aligned.WriteCSV(&buf))
+	assert.Equal(t, "Item,Qty,Price\nPencil,12,0.50\n\"Ink, black\",3,4.25\n", buf.String())
+
+	buf.Reset()
+	assert.Equal(t, nil, aligned.WriteJSON(&buf))
+	assert.Equal(t, true, bytes.Contains(buf.Bytes(), []byte(`"Text":"Pencil"`)))
+}
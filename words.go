@@ -0,0 +1,372 @@
+// Copyright 2014 The Go Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pdf
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"golang.org/x/text/unicode/bidi"
+)
+
+// A Word is a run of glyphs shown on the page with no space between them.
+type Word struct {
+	Font     string  // the font of the first glyph
+	FontSize float64 // the font size of the first glyph, in points
+	X        float64 // the X coordinate of the first glyph's origin, or of the left end of a right-to-left word, in points
+	Y        float64 // the Y coordinate of the baseline at X, in points
+	W        float64 // the advance width of the word along its baseline, in points
+	S        string  // the word's UTF-8 text
+	Rect     Rect    // the bounding box of the word
+	Angle    float64 // the direction of the baseline, in degrees counterclockwise from the X axis, as in Text
+
+	Marked *MarkedContent // the marked-content sequence containing the first glyph, or nil
+}
+
+// Thresholds for segmenting glyphs into words, as fractions of the font size.
+const (
+	wordGap   = 0.15 // horizontal gaps wider than this separate words
+	wordShift = 0.5  // baseline shifts larger than this start a new line
+	ascender  = 0.8  // height of the bounding box above the baseline
+	descender = 0.2  // depth of the bounding box below the baseline
+)
+
+// angleTolerance is how far apart, in degrees, the directions of two
+// baselines may be for glyphs along them to be in the same line.
+const angleTolerance = 1.0
+
+// Words returns the words on the page, in the order the content stream shows them.
+//
+// The glyphs of a word are those that follow one another along the same
+// baseline with no space between them. A space is inferred wherever the page
+// shows a whitespace glyph, or the gap between one glyph's advance and the next
+// glyph's origin exceeds a fraction of the font size, which is what results
+// from a large negative TJ adjustment or from word spacing (Tw).
+// Lines holding right-to-left text, such as Arabic or Hebrew, are put into
+// reading order by the Unicode Bidirectional Algorithm, whether the page
+// shows their glyphs in that order or from left to right.
+// Glyphs on rotated or vertical baselines are grouped by the direction of
+// their baseline, Text.Angle, and split into words along it as if upright.
+// Word bounding boxes cover the boxes of their glyphs, as Text.Quad gives them.
+// The words are dehyphenated and normalized as set by the Reader's SetExtractOptions.
+func (p Page) Words() []Word {
+	p = p.fixOptions()
+	ws := p.pageWords()
+	p.display().flipWords(ws)
+	return ws
+}
+
+// pageWords returns the words on the page, in upright space if the
+// extraction options select display space.
+func (p Page) pageWords() []Word {
+	return p.extractOptions().finishWords(words(p.content(nil).Text))
+}
+
+func words(text []Text) []Word {
+	out, _ := segmentWords(text)
+	return out
+}
+
+// segmentWords splits text into words, as Page.Words does, and returns
+// them along with the indices in text of the glyphs of each word, in
+// reading order.
+func segmentWords(text []Text) ([]Word, [][]int) {
+	var (
+		out    []Word
+		glyphs [][]int
+	)
+
+	// Split the glyphs into runs along a baseline. Each run is turned
+	// upright, so that its baseline is horizontal, and split into words
+	// there. Runs holding right-to-left text are put into reading order
+	// separately.
+	var (
+		run []Text
+		idx []int // the index in text of each glyph of run
+	)
+	flush := func() {
+		if len(run) == 0 {
+			return
+		}
+		angle := run[0].Angle
+		for i := range run {
+			run[i] = uprightText(run[i], angle)
+		}
+		var (
+			ws []Word
+			gs [][]int
+		)
+		if hasRTL(run) {
+			ws, gs = rtlWords(run)
+		} else {
+			ws, gs = ltrWords(run)
+		}
+		for i, w := range ws {
+			for j := range gs[i] {
+				gs[i][j] = idx[gs[i][j]]
+			}
+			glyphs = append(glyphs, gs[i])
+			if w.Rect == (Rect{}) {
+				size := math.Abs(w.FontSize)
+				w.Rect = Rect{
+					Point{math.Min(w.X, w.X+w.W), w.Y - descender*size},
+					Point{math.Max(w.X, w.X+w.W), w.Y + ascender*size},
+				}
+			}
+			out = append(out, rotateWord(w, angle))
+		}
+		run, idx = run[:0], idx[:0]
+	}
+	for i, t := range text {
+		if t.S == "\n" { // the end of a TJ array, which need not end a word
+			continue
+		}
+		if len(run) > 0 {
+			prev := text[idx[len(idx)-1]]
+			size := math.Max(math.Abs(prev.FontSize), math.Abs(t.FontSize))
+			if !sameDirection(prev.Angle, t.Angle) || math.Abs(baseline(t.X, t.Y, prev.Angle)-baseline(prev.X, prev.Y, prev.Angle)) > wordShift*size {
+				flush()
+			}
+		}
+		run = append(run, t)
+		idx = append(idx, i)
+	}
+	flush()
+	return out, glyphs
+}
+
+// sameDirection reports whether baselines in the directions a and b,
+// in degrees, run the same way.
+func sameDirection(a, b float64) bool {
+	d := math.Mod(math.Abs(a-b), 360)
+	return math.Min(d, 360-d) <= angleTolerance
+}
+
+// rotate returns p rotated clockwise by angle degrees about the origin,
+// which brings a baseline in the direction angle to the horizontal.
+func rotate(p Point, angle float64) Point {
+	if angle == 0 {
+		return p
+	}
+	sin, cos := math.Sincos(angle * math.Pi / 180)
+	return Point{p.X*cos + p.Y*sin, p.Y*cos - p.X*sin}
+}
+
+// baseline returns the height of the baseline in the direction angle
+// through (x, y), measured once the baseline is turned upright.
+func baseline(x, y, angle float64) float64 {
+	return rotate(Point{x, y}, angle).Y
+}
+
+// uprightText returns t turned by angle degrees clockwise, so that a
+// baseline in the direction angle is horizontal. Its width becomes the
+// length of its advance along the baseline.
+func uprightText(t Text, angle float64) Text {
+	if angle == 0 {
+		return t
+	}
+	o := rotate(Point{t.X, t.Y}, angle)
+	t.X, t.Y = o.X, o.Y
+	if t.Quad != ([4]Point{}) {
+		for i := range t.Quad {
+			t.Quad[i] = rotate(t.Quad[i], angle)
+		}
+		t.W = t.Quad[1].X - t.Quad[0].X
+	} else if cos := math.Cos(angle * math.Pi / 180); math.Abs(cos) > 1e-6 {
+		t.W /= cos
+	}
+	return t
+}
+
+// rotateWord returns w, made from upright glyphs, turned back by
+// angle degrees counterclockwise onto the page.
+func rotateWord(w Word, angle float64) Word {
+	w.Angle = angle
+	if angle == 0 {
+		return w
+	}
+	o := rotate(Point{w.X, w.Y}, -angle)
+	w.X, w.Y = o.X, o.Y
+	r := w.Rect
+	var b Rect
+	for i, p := range []Point{r.Min, {r.Max.X, r.Min.Y}, r.Max, {r.Min.X, r.Max.Y}} {
+		p = rotate(p, -angle)
+		if i == 0 {
+			b = Rect{p, p}
+		} else {
+			b = b.union(Rect{p, p})
+		}
+	}
+	w.Rect = b
+	return w
+}
+
+// sameBaseline reports whether the words a and b run the same way
+// along the same baseline.
+func sameBaseline(a, b Word) bool {
+	size := math.Max(math.Abs(a.FontSize), math.Abs(b.FontSize))
+	return sameDirection(a.Angle, b.Angle) && math.Abs(baseline(a.X, a.Y, a.Angle)-baseline(b.X, b.Y, a.Angle)) <= wordShift*size
+}
+
+// ltrWords splits glyphs shown along a baseline into words,
+// in the order the glyphs are shown, and returns them along with
+// the indices in text of the glyphs of each word.
+func ltrWords(text []Text) ([]Word, [][]int) {
+	var (
+		out    []Word
+		glyphs [][]int
+		w      *Word
+		g      []int
+		end    float64 // where the previous glyph's advance ended
+	)
+	flush := func() {
+		if w != nil {
+			out = append(out, *w)
+			glyphs = append(glyphs, g)
+			w, g = nil, nil
+		}
+	}
+	for i, t := range text {
+		if strings.TrimSpace(t.S) == "" {
+			flush()
+			continue
+		}
+		if w != nil {
+			size := math.Max(math.Abs(w.FontSize), math.Abs(t.FontSize))
+			gap := t.X - end
+			if math.Abs(t.Y-w.Y) > wordShift*size || gap > wordGap*size || gap < -wordShift*size {
+				flush()
+			}
+		}
+		if w == nil {
+			w = &Word{Font: t.Font, FontSize: t.FontSize, X: t.X, Y: t.Y, Marked: t.Marked}
+		}
+		w.S += t.S
+		w.Rect = glyphRect(w.Rect, t)
+		g = append(g, i)
+		end = t.X + t.W
+		w.W = end - w.X
+	}
+	flush()
+	return out, glyphs
+}
+
+// rtlWords splits glyphs shown along a baseline, some of them right to
+// left, into words in reading order. Producers show such text in either
+// order, so the glyphs are first sorted left to right and then reordered
+// by the bidirectional algorithm. The X of each word is its left end.
+// The indices in text of the glyphs of each word are returned with the words.
+func rtlWords(text []Text) ([]Word, [][]int) {
+	order := make([]int, len(text))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return text[order[i]].X < text[order[j]].X
+	})
+
+	// Units are glyphs, and spaces shown or inferred from the gaps
+	// between them, which are -1.
+	var (
+		units   []int
+		classes []bidi.Class
+		end     float64
+	)
+	for _, i := range order {
+		t := text[i]
+		space := strings.TrimSpace(t.S) == ""
+		if !space && len(units) > 0 && units[len(units)-1] >= 0 {
+			if gap := t.X - end; gap > wordGap*math.Abs(t.FontSize) {
+				units = append(units, -1)
+				classes = append(classes, bidi.WS)
+			}
+		}
+		if space {
+			if len(units) > 0 && units[len(units)-1] >= 0 {
+				units = append(units, -1)
+				classes = append(classes, bidi.WS)
+			}
+			continue
+		}
+		units = append(units, i)
+		classes = append(classes, bidiClass(t.S))
+		end = t.X + t.W
+	}
+
+	var (
+		out    []Word
+		glyphs [][]int
+		w      *Word
+		g      []int
+	)
+	for _, u := range visualToLogical(classes) {
+		if units[u] < 0 {
+			if w != nil {
+				out = append(out, *w)
+				glyphs = append(glyphs, g)
+				w, g = nil, nil
+			}
+			continue
+		}
+		t := text[units[u]]
+		lo, hi := math.Min(t.X, t.X+t.W), math.Max(t.X, t.X+t.W)
+		if w == nil {
+			w = &Word{Font: t.Font, FontSize: t.FontSize, X: lo, Y: t.Y, Marked: t.Marked}
+		}
+		w.S += t.S
+		w.Rect = glyphRect(w.Rect, t)
+		g = append(g, units[u])
+		left, right := math.Min(w.X, lo), math.Max(w.X+w.W, hi)
+		w.X, w.W = left, right-left
+	}
+	if w != nil {
+		out = append(out, *w)
+		glyphs = append(glyphs, g)
+	}
+	return out, glyphs
+}
+
+// glyphRect returns the union of r, which is empty if it is the zero Rect,
+// with the bounds of the glyph t, if the glyph's box is known.
+func glyphRect(r Rect, t Text) Rect {
+	if t.Quad == ([4]Point{}) {
+		return r
+	}
+	if r == (Rect{}) {
+		return t.Bounds()
+	}
+	return r.union(t.Bounds())
+}
+
+// hasRTL reports whether any glyph in text shows right-to-left characters.
+func hasRTL(text []Text) bool {
+	for _, t := range text {
+		if isRTL(t.S) {
+			return true
+		}
+	}
+	return false
+}
+
+// plainText joins words into text. Words on the same baseline
+// are separated by a space; each line ends with a newline.
+func plainText(words []Word) string {
+	var b strings.Builder
+	for i, w := range words {
+		if i > 0 {
+			if sameBaseline(words[i-1], w) {
+				b.WriteString(" ")
+			} else {
+				b.WriteString("\n")
+			}
+		}
+		b.WriteString(w.S)
+	}
+	if len(words) > 0 {
+		b.WriteString("\n")
+	}
+	return b.String()
+}
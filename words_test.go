@@ -0,0 +1,83 @@
+package pdf
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+func TestWords(t *testing.T) {
+	glyphs := func(s string, x, y, w float64) []Text {
+		var out []Text
+		for _, r := range s {
+			out = append(out, Text{Font: "F1", FontSize: 10, X: x, Y: y, W: w, S: string(r)})
+			x += w
+		}
+		return out
+	}
+	var text []Text
+	text = append(text, glyphs("Tot", 100, 700, 5)...)
+	text = append(text, glyphs("al", 114.7, 700, 5)...) // kerning, not a space
+	text = append(text, Text{S: "\n", FontSize: 10, X: 125, Y: 700})
+	text = append(text, glyphs("amount", 128, 700, 5)...)   // TJ gap of 0.3 em
+	text = append(text, glyphs("due:", 160.5, 700, 5)...)   // word spacing
+	text = append(text, glyphs(" ", 180.5, 700, 2.5)...)    // an explicit space
+	text = append(text, glyphs("42", 183, 700, 5)...)       // after the space
+	text = append(text, glyphs("Next", 100, 688, 5)...)     // a new line
+	text = append(text, glyphs("line", 100+4*5, 688, 5)...) // runs on from Next
+	text = append(text, glyphs("back", 90, 688, 5)...)      // moves back left
+	text = append(text, glyphs("x", 110+20, 688-30, 5)...)  // and another line
+
+	words := words(text)
+	var got []string
+	for _, w := range words {
+		got = append(got, w.S)
+	}
+	assert.Equal(t, []string{"Total", "amount", "due:", "42", "Nextline", "back", "x"}, got)
+	assert.Equal(t, Rect{Point{100, 698}, Point{124.7, 708}}, words[0].Rect)
+	assert.Equal(t, "Total amount due: 42\nNextline back\nx\n", plainText(words))
+}
+
+// upwardGlyphs returns the glyphs of s on a baseline turned 90°
+// counterclockwise from (x, y), reading upward, each advancing by
+// 5 points. Their horizontal advance is zero; Quad gives their extent.
+func upwardGlyphs(s string, x, y float64) []Text {
+	var out []Text
+	for _, r := range s {
+		out = append(out, Text{Font: "F1", FontSize: 10, X: x, Y: y, S: string(r), Angle: 90,
+			Quad: [4]Point{{x + 2, y}, {x + 2, y + 5}, {x - 8, y + 5}, {x - 8, y}}})
+		y += 5
+	}
+	return out
+}
+
+func TestWordsRotated(t *testing.T) {
+	var text []Text
+	text = append(text, upwardGlyphs("Side", 100, 200)...)
+	text = append(text, upwardGlyphs("note", 100, 222)...)
+	text = append(text, upwardGlyphs("here", 112, 200)...) // the next line, to the right
+
+	words := words(text)
+	var got []string
+	for _, w := range words {
+		got = append(got, w.S)
+	}
+	assert.Equal(t, []string{"Side", "note", "here"}, got)
+	assert.Equal(t, Word{Font: "F1", FontSize: 10, X: 100, Y: 200, W: 20, S: "Side",
+		Rect: Rect{Point{92, 200}, Point{102, 220}}, Angle: 90}, words[0])
+	assert.Equal(t, "Side note\nhere\n", plainText(words))
+
+	l := layout(words)
+	var lines []string
+	for _, b := range l.Blocks {
+		for _, line := range b.Lines {
+			var s []string
+			for _, w := range line.Words {
+				s = append(s, w.S)
+			}
+			lines = append(lines, strings.Join(s, " "))
+		}
+	}
+	assert.Equal(t, []string{"Side note", "here"}, lines)
+}
@@ -0,0 +1,57 @@
+// Copyright 2014 The Go Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Form XObjects. See PDF 32000-1:2008, §8.10.
+
+package pdf
+
+import "math"
+
+// maxFormDepth limits how deeply forms painted by
+// other forms are interpreted, in case of runaway nesting.
+const maxFormDepth = 32
+
+// isForm reports whether v is a form XObject.
+func isForm(v Value) bool {
+	return v.Kind() == Stream && v.Key("Subtype").Name() == "Form"
+}
+
+// formMatrix returns the matrix mapping the space of form to the user
+// space in which it is painted: its Matrix entry, or the identity.
+func formMatrix(form Value) matrix {
+	v := form.Key("Matrix")
+	if v.Len() != 6 {
+		return ident
+	}
+	var m matrix
+	for i := 0; i < 6; i++ {
+		m[i/2][i%2] = v.Index(i).Float64()
+	}
+	m[2][2] = 1
+	return m
+}
+
+// formClip returns the clipping bounds in effect while form is painted
+// with the transformation ctm, which includes the form's Matrix, inside
+// the bounds clip: the image of the form's BBox within clip, if any.
+func formClip(form Value, ctm matrix, clip *Rect) *Rect {
+	bbox := form.Key("BBox")
+	if bbox.Len() != 4 {
+		return clip
+	}
+	r := ctm.rect(pageBox(bbox, Rect{}))
+	if clip != nil {
+		r = intersect(r, *clip)
+	}
+	return &r
+}
+
+// intersect returns the intersection of r and s, which is
+// empty, with Min beyond Max, if they do not overlap.
+func intersect(r, s Rect) Rect {
+	return Rect{
+		Point{math.Max(r.Min.X, s.Min.X), math.Max(r.Min.Y, s.Min.Y)},
+		Point{math.Min(r.Max.X, s.Max.X), math.Min(r.Max.Y, s.Max.Y)},
+	}
+}
@@ -0,0 +1,55 @@
+package pdf
+
+import (
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+func TestFormXObject(t *testing.T) {
+	r := newTestPDF(t,
+		`<< /Type /Catalog /Pages 2 0 R >>`,
+		`<< /Type /Pages /Kids [3 0 R] /Count 1 >>`,
+		`<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 4 0 R
+		   /Resources << /Font << /F1 << /Type /Font /Subtype /Type1 /BaseFont /Helvetica >> >>
+		                 /XObject << /Fm1 5 0 R /Fm2 6 0 R >> >> >>`,
+		`stream
+BT /F1 12 Tf 1 0 0 1 72 700 Tm (Page) Tj ET
+q 2 0 0 2 10 10 cm 0 0 5 5 re f Q
+/Fm1 Do /Fm2 Do`,
+		`<< /Type /XObject /Subtype /Form /BBox [0 0 200 50] /Matrix [1 0 0 1 100 500]
+		   /Resources << /Font << /F1 << /Type /Font /Subtype /Type1 /BaseFont /Courier >> >> >> >>
+stream
+BT /F1 10 Tf 1 0 0 1 10 10 Tm (Inside) Tj 1 0 0 1 300 10 Tm (Outside) Tj ET
+0 0 20 20 re f`,
+		`<< /Type /XObject /Subtype /Form /BBox [0 0 612 792]
+		   /Resources << /Font << /F1 << /Type /Font /Subtype /Type1 /BaseFont /Helvetica >> >>
+		                 /XObject << /Self 6 0 R >> >> >>
+stream
+BT /F1 12 Tf 1 0 0 1 72 400 Tm (Loop) Tj ET
+/Self Do`,
+	)
+	p := r.Page(1)
+	text, err := p.GetPlainText(nil)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "Page\nInside\nLoop\n", text)
+
+	c := p.Content()
+	var inside Text
+	for _, t := range c.Text {
+		if t.S == "I" {
+			inside = t
+		}
+	}
+	assert.Equal(t, "Courier", inside.Font)
+	assert.Equal(t, 110.0, inside.X)
+	assert.Equal(t, 510.0, inside.Y)
+	// Rectangles on the page and in forms alike are in page space.
+	assert.Equal(t, []Rect{{Point{10, 10}, Point{20, 20}}, {Point{100, 500}, Point{120, 520}}}, c.Rect)
+
+	rows, err := p.GetTextByRow()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 3, len(rows))
+	assert.Equal(t, 510.0, rows[1].Position)
+	assert.Equal(t, "Inside", rows[1].Content[0].S)
+}